@@ -0,0 +1,169 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"watch_bot/bots"
+	"watch_bot/dao"
+	"watch_bot/duty"
+
+	botgolang "github.com/mail-ru-im/bot-golang"
+)
+
+// alertmanagerActor is recorded in the duty history when a webhook selects the duty person
+const alertmanagerActor = "alertmanager"
+
+// maxAlertmanagerPayload limits the size of an accepted webhook body
+const maxAlertmanagerPayload = 1 << 20
+
+// AlertmanagerConfig contains configuration for the Alertmanager webhook receiver
+type AlertmanagerConfig struct {
+	GetCurrentDuty func(actorUserId string) (*duty.DutyResult, error)
+	// PeekCurrentDuty finds the duty person without assigning the day, it is used when IsWorkingDay is false
+	PeekCurrentDuty func() (*duty.DutyResult, error)
+	// IsWorkingDay reports whether today is a working day, nil treats every day as one
+	IsWorkingDay  func() bool
+	MessagesChan  chan bots.Message
+	SupportChatId string
+	// RecordPage stores the page for the duty statistics, nil does not record anything
+	RecordPage func(dutyID, actorUserId, role string) error
+}
+
+// AlertmanagerPayload is the webhook body sent by Prometheus Alertmanager (version 4)
+type AlertmanagerPayload struct {
+	Version           string            `json:"version"`
+	GroupKey          string            `json:"groupKey"`
+	Status            string            `json:"status"`
+	Receiver          string            `json:"receiver"`
+	GroupLabels       map[string]string `json:"groupLabels"`
+	CommonLabels      map[string]string `json:"commonLabels"`
+	CommonAnnotations map[string]string `json:"commonAnnotations"`
+	ExternalURL       string            `json:"externalURL"`
+	Alerts            []Alert           `json:"alerts"`
+}
+
+// Alert is a single alert of an Alertmanager notification
+type Alert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     string            `json:"startsAt"`
+	EndsAt       string            `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+// Alertmanager serves POST /webhooks/alertmanager and forwards alerts to the current duty person and the support chat
+func Alertmanager(config AlertmanagerConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var payload AlertmanagerPayload
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAlertmanagerPayload)).Decode(&payload); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid Alertmanager payload")
+			return
+		}
+		if len(payload.Alerts) == 0 {
+			writeError(w, http.StatusBadRequest, "payload contains no alerts")
+			return
+		}
+
+		// alerts on days off reach the duty person without entering the day into the rotation
+		var result *duty.DutyResult
+		var err error
+		if config.IsWorkingDay != nil && !config.IsWorkingDay() && config.PeekCurrentDuty != nil {
+			result, err = config.PeekCurrentDuty()
+		} else {
+			result, err = config.GetCurrentDuty(alertmanagerActor)
+		}
+		if err != nil {
+			log.Printf("failed to get current duty for alert %s: %v", payload.GroupKey, err)
+			writeError(w, http.StatusInternalServerError, "failed to get current duty")
+			return
+		}
+
+		text := FormatAlertmanagerMessage(payload)
+		dutyID := ""
+		if result != nil {
+			dutyID = result.DutyID
+			if config.RecordPage != nil {
+				if err := config.RecordPage(dutyID, alertmanagerActor, dao.RolePrimary); err != nil {
+					log.Printf("failed to record page of %s for alert %s: %v", dutyID, payload.GroupKey, err)
+				}
+			}
+			bots.SendNonBlocking(config.MessagesChan, bots.Message{
+				ChatId:    dutyID,
+				Text:      text,
+				ParseMode: string(botgolang.ParseModeHTML),
+			})
+		}
+		if config.SupportChatId != "" {
+			supportText := "No duty assigned for today\n\n" + text
+			if dutyID != "" {
+				supportText = fmt.Sprintf("On duty: @[%s]\n\n%s", dutyID, text)
+			}
+			bots.SendNonBlocking(config.MessagesChan, bots.Message{
+				ChatId:    config.SupportChatId,
+				Text:      supportText,
+				ParseMode: string(botgolang.ParseModeHTML),
+			})
+		}
+
+		writeJSON(w, http.StatusOK, struct {
+			DutyID string `json:"duty_id,omitempty"`
+		}{DutyID: dutyID})
+	}
+}
+
+// FormatAlertmanagerMessage renders the notification as HTML with the state, labels and annotations of each alert
+func FormatAlertmanagerMessage(payload AlertmanagerPayload) string {
+	var builder strings.Builder
+	firing := 0
+	for _, alert := range payload.Alerts {
+		if alert.Status == "firing" {
+			firing++
+		}
+	}
+	if payload.Status == "resolved" {
+		fmt.Fprintf(&builder, "✅ <b>RESOLVED</b> (%d)", len(payload.Alerts))
+	} else {
+		fmt.Fprintf(&builder, "🔥 <b>FIRING</b> (%d of %d)", firing, len(payload.Alerts))
+	}
+	if name := payload.CommonLabels["alertname"]; name != "" {
+		fmt.Fprintf(&builder, " %s", html.EscapeString(name))
+	}
+	builder.WriteString("\n")
+
+	for _, alert := range payload.Alerts {
+		state := "🔥 firing"
+		if alert.Status == "resolved" {
+			state = "✅ resolved"
+		}
+		fmt.Fprintf(&builder, "\n<b>%s</b> %s\n", html.EscapeString(alert.Labels["alertname"]), state)
+		writeAlertFields(&builder, "Labels", alert.Labels)
+		writeAlertFields(&builder, "Annotations", alert.Annotations)
+		if alert.GeneratorURL != "" {
+			fmt.Fprintf(&builder, "Source: %s\n", html.EscapeString(alert.GeneratorURL))
+		}
+	}
+	return strings.TrimSuffix(builder.String(), "\n")
+}
+
+func writeAlertFields(builder *strings.Builder, title string, fields map[string]string) {
+	if len(fields) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintf(builder, "%s:\n", title)
+	for _, key := range keys {
+		fmt.Fprintf(builder, "  %s: %s\n", html.EscapeString(key), html.EscapeString(fields[key]))
+	}
+}
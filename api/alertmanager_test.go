@@ -0,0 +1,167 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"watch_bot/bots"
+	"watch_bot/duty"
+)
+
+const firingPayload = `{
+	"version": "4",
+	"groupKey": "{}:{alertname=\"HighLatency\"}",
+	"status": "firing",
+	"commonLabels": {"alertname": "HighLatency"},
+	"alerts": [
+		{
+			"status": "firing",
+			"labels": {"alertname": "HighLatency", "severity": "critical", "service": "api<1>"},
+			"annotations": {"summary": "p99 above 2s"},
+			"generatorURL": "http://prometheus/graph"
+		},
+		{
+			"status": "resolved",
+			"labels": {"alertname": "HighLatency", "service": "web"}
+		}
+	]
+}`
+
+func postAlert(handler http.HandlerFunc, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodPost, "/webhooks/alertmanager", strings.NewReader(body)))
+	return recorder
+}
+
+func TestAlertmanager_NotifiesDutyAndSupport(t *testing.T) {
+	messagesChan := make(chan bots.Message, 10)
+	var actor, paged string
+	handler := Alertmanager(AlertmanagerConfig{
+		GetCurrentDuty: func(actorUserId string) (*duty.DutyResult, error) {
+			actor = actorUserId
+			return &duty.DutyResult{DutyID: "alice"}, nil
+		},
+		MessagesChan:  messagesChan,
+		SupportChatId: "support",
+		RecordPage: func(dutyID, actorUserId, role string) error {
+			paged = dutyID + "/" + role
+			return nil
+		},
+	})
+
+	recorder := postAlert(handler, firingPayload)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if actor != alertmanagerActor {
+		t.Errorf("expected actor %q, got %q", alertmanagerActor, actor)
+	}
+	if paged != "alice/primary" {
+		t.Errorf("expected the page of alice to be recorded, got %q", paged)
+	}
+
+	close(messagesChan)
+	var messages []bots.Message
+	for message := range messagesChan {
+		messages = append(messages, message)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(messages))
+	}
+	if messages[0].ChatId != "alice" || messages[1].ChatId != "support" {
+		t.Fatalf("unexpected recipients %q and %q", messages[0].ChatId, messages[1].ChatId)
+	}
+	if !strings.Contains(messages[1].Text, "@[alice]") {
+		t.Errorf("expected support message to mention alice, got %q", messages[1].Text)
+	}
+	for _, expected := range []string{"FIRING</b> (1 of 2) HighLatency", "severity: critical", "service: api&lt;1&gt;", "summary: p99 above 2s", "✅ resolved"} {
+		if !strings.Contains(messages[0].Text, expected) {
+			t.Errorf("expected message to contain %q, got:\n%s", expected, messages[0].Text)
+		}
+	}
+}
+
+func TestAlertmanager_NoDutyNotifiesSupportOnly(t *testing.T) {
+	messagesChan := make(chan bots.Message, 10)
+	handler := Alertmanager(AlertmanagerConfig{
+		GetCurrentDuty: func(string) (*duty.DutyResult, error) { return nil, nil },
+		MessagesChan:   messagesChan,
+		SupportChatId:  "support",
+	})
+
+	if recorder := postAlert(handler, firingPayload); recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	if len(messagesChan) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(messagesChan))
+	}
+	if message := <-messagesChan; message.ChatId != "support" || !strings.HasPrefix(message.Text, "No duty assigned") {
+		t.Errorf("unexpected message %+v", message)
+	}
+}
+
+func TestAlertmanager_RejectsInvalidPayload(t *testing.T) {
+	handler := Alertmanager(AlertmanagerConfig{
+		GetCurrentDuty: func(string) (*duty.DutyResult, error) {
+			t.Fatal("duty must not be resolved for an invalid payload")
+			return nil, nil
+		},
+	})
+
+	for _, body := range []string{"not json", `{"status":"firing","alerts":[]}`} {
+		if recorder := postAlert(handler, body); recorder.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for %q, got %d", body, recorder.Code)
+		}
+	}
+}
+
+func TestAlertmanager_ServiceError(t *testing.T) {
+	handler := Alertmanager(AlertmanagerConfig{
+		GetCurrentDuty: func(string) (*duty.DutyResult, error) { return nil, errors.New("db down") },
+	})
+
+	if recorder := postAlert(handler, firingPayload); recorder.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", recorder.Code)
+	}
+}
+
+func TestFormatAlertmanagerMessage_Resolved(t *testing.T) {
+	text := FormatAlertmanagerMessage(AlertmanagerPayload{
+		Status: "resolved",
+		Alerts: []Alert{{Status: "resolved", Labels: map[string]string{"alertname": "DiskFull"}}},
+	})
+	if !strings.HasPrefix(text, "✅ <b>RESOLVED</b> (1)") {
+		t.Errorf("unexpected message:\n%s", text)
+	}
+}
+
+func TestAlertmanager_DayOffDoesNotAssignDuty(t *testing.T) {
+	messagesChan := make(chan bots.Message, 10)
+	handler := Alertmanager(AlertmanagerConfig{
+		GetCurrentDuty: func(actorUserId string) (*duty.DutyResult, error) {
+			t.Error("expected no assignment on a day off")
+			return nil, nil
+		},
+		PeekCurrentDuty: func() (*duty.DutyResult, error) {
+			return &duty.DutyResult{DutyID: "alice"}, nil
+		},
+		IsWorkingDay:  func() bool { return false },
+		MessagesChan:  messagesChan,
+		SupportChatId: "support",
+	})
+
+	recorder := postAlert(handler, firingPayload)
+	if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), "alice") {
+		t.Fatalf("expected alice to be paged, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	close(messagesChan)
+	var recipients []string
+	for message := range messagesChan {
+		recipients = append(recipients, message.ChatId)
+	}
+	if len(recipients) != 2 || recipients[0] != "alice" || recipients[1] != "support" {
+		t.Errorf("expected the alert to reach alice and the support chat, got %v", recipients)
+	}
+}
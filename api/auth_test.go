@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireToken(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		want          int
+	}{
+		{"valid token", "secret", "Bearer secret", http.StatusNoContent},
+		{"wrong token", "secret", "Bearer other", http.StatusUnauthorized},
+		{"missing header", "secret", "", http.StatusUnauthorized},
+		{"wrong scheme", "secret", "Basic secret", http.StatusUnauthorized},
+		{"token not configured", "", "Bearer ", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/api/v1/page", nil)
+			if tt.authorization != "" {
+				request.Header.Set("Authorization", tt.authorization)
+			}
+			recorder := httptest.NewRecorder()
+			RequireToken(tt.token)(ok).ServeHTTP(recorder, request)
+			if recorder.Code != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, recorder.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"watch_bot/duty"
+	"watch_bot/ical"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// allDutiesCalendar is the file name of the feed that contains every duty person
+const allDutiesCalendar = "duty"
+
+// DutyDaysFunc returns recorded and projected duty days ordered by date
+type DutyDaysFunc func() ([]duty.ScheduledDay, error)
+
+// Calendar serves GET /calendar/{file} where file is duty.ics for the whole rotation
+// or <dutyId>.ics for the days of a single person
+func Calendar(getDutyDays DutyDaysFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		file := chi.URLParam(r, "file")
+		dutyID, ok := strings.CutSuffix(file, ".ics")
+		if !ok || dutyID == "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		days, err := getDutyDays()
+		if err != nil {
+			log.Printf("failed to get duty days for calendar: %v", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		name := "Duty rotation"
+		if dutyID != allDutiesCalendar {
+			name = "Duty of " + dutyID
+		}
+		var events []ical.Event
+		for _, day := range days {
+			if dutyID != allDutiesCalendar && day.DutyID != dutyID {
+				continue
+			}
+			events = append(events, dutyEvent(day))
+		}
+
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", file))
+		if err := ical.WriteCalendar(w, name, events); err != nil {
+			log.Printf("failed to write calendar: %v", err)
+		}
+	}
+}
+
+func dutyEvent(day duty.ScheduledDay) ical.Event {
+	description := "Assigned"
+	if day.Projected {
+		description = "Projected from the current rotation, may change"
+	}
+	if day.ReplacedDutyID != "" {
+		description += fmt.Sprintf(", replaces %s", day.ReplacedDutyID)
+	}
+
+	date := day.Date.Format("2006-01-02")
+	return ical.Event{
+		UID:         fmt.Sprintf("%s-%s@watch_bot", date, day.DutyID),
+		Start:       day.Date,
+		End:         day.Date.AddDate(0, 0, 1),
+		Summary:     "Duty: " + day.DutyID,
+		Description: description,
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"watch_bot/duty"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func newCalendarRouter(getDutyDays DutyDaysFunc) http.Handler {
+	router := chi.NewRouter()
+	router.Get("/calendar/{file}", Calendar(getDutyDays))
+	return router
+}
+
+func calendarDays() ([]duty.ScheduledDay, error) {
+	return []duty.ScheduledDay{
+		{Date: time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC), DutyID: "alice"},
+		{Date: time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC), DutyID: "bob@corp.ru", ReplacedDutyID: "alice"},
+		{Date: time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC), DutyID: "alice", Projected: true},
+	}, nil
+}
+
+func TestCalendar_AllDuties(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	newCalendarRouter(calendarDays).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/calendar/duty.ics", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/calendar") {
+		t.Errorf("unexpected content type %q", contentType)
+	}
+	body := recorder.Body.String()
+	if strings.Count(body, "BEGIN:VEVENT") != 3 {
+		t.Errorf("expected 3 events, got:\n%s", body)
+	}
+	if !strings.Contains(body, "DESCRIPTION:Projected from the current rotation\\, may change") {
+		t.Errorf("expected projected day to be marked, got:\n%s", body)
+	}
+}
+
+func TestCalendar_SinglePerson(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	newCalendarRouter(calendarDays).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/calendar/bob@corp.ru.ics", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	body := recorder.Body.String()
+	if strings.Count(body, "BEGIN:VEVENT") != 1 {
+		t.Errorf("expected 1 event, got:\n%s", body)
+	}
+	if !strings.Contains(body, "DTSTART;VALUE=DATE:20260108") || !strings.Contains(body, "replaces alice") {
+		t.Errorf("unexpected event, got:\n%s", body)
+	}
+}
+
+func TestCalendar_RequiresIcsExtension(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	newCalendarRouter(calendarDays).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/calendar/alice", nil))
+
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", recorder.Code)
+	}
+}
+
+func TestCalendar_ServiceError(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	newCalendarRouter(func() ([]duty.ScheduledDay, error) {
+		return nil, errors.New("db down")
+	}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/calendar/duty.ics", nil))
+
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", recorder.Code)
+	}
+}
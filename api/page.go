@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"strings"
+	"watch_bot/bots/commands"
+	"watch_bot/duty"
+)
+
+// pageActor is recorded in the duty history when the page endpoint selects the duty person
+const pageActor = "api"
+
+const maxPagePayload = 64 << 10
+
+// Severities accepted by the page endpoint
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+var severityIcons = map[string]string{
+	SeverityInfo:     "ℹ️",
+	SeverityWarning:  "⚠️",
+	SeverityCritical: "🚨",
+}
+
+// CallDutyFunc calls the current duty person, see commands.DutyCommand.Call
+type CallDutyFunc func(call commands.DutyCall) (*duty.DutyResult, error)
+
+type pageRequest struct {
+	Title    string `json:"title"`
+	Body     string `json:"body"`
+	Severity string `json:"severity"`
+	ChatId   string `json:"chat_id"`
+}
+
+type pageResponse struct {
+	DutyID          string `json:"duty_id"`
+	IsNewAssignment bool   `json:"is_new_assignment"`
+	SecondaryDutyID string `json:"secondary_duty_id,omitempty"`
+}
+
+// Page serves POST /api/v1/page which calls the duty person like the \duty command does
+func Page(callDuty CallDutyFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request pageRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxPagePayload)).Decode(&request); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON body")
+			return
+		}
+		request.Title = strings.TrimSpace(request.Title)
+		if request.Title == "" {
+			writeError(w, http.StatusBadRequest, "title is required")
+			return
+		}
+		if request.Severity == "" {
+			request.Severity = SeverityCritical
+		}
+		if _, ok := severityIcons[request.Severity]; !ok {
+			writeError(w, http.StatusBadRequest, "severity must be one of info, warning, critical")
+			return
+		}
+
+		result, err := callDuty(commands.DutyCall{
+			ActorUserId: pageActor,
+			Details:     formatPage(request),
+			ChatId:      strings.TrimSpace(request.ChatId),
+		})
+		if errors.Is(err, commands.ErrOutsideWorkingHours) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		if err != nil {
+			log.Printf("failed to page duty person: %v", err)
+			writeError(w, http.StatusInternalServerError, "failed to get current duty")
+			return
+		}
+		if result == nil {
+			writeError(w, http.StatusNotFound, "no duty assigned for today")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, pageResponse{
+			DutyID:          result.DutyID,
+			IsNewAssignment: result.IsNewAssignment,
+			SecondaryDutyID: result.SecondaryDutyID,
+		})
+	}
+}
+
+func formatPage(request pageRequest) string {
+	text := fmt.Sprintf("%s <b>[%s] %s</b>", severityIcons[request.Severity],
+		strings.ToUpper(request.Severity), html.EscapeString(request.Title))
+	if body := strings.TrimSpace(request.Body); body != "" {
+		text += "\n" + html.EscapeString(body)
+	}
+	return text
+}
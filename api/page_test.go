@@ -0,0 +1,83 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"watch_bot/bots/commands"
+	"watch_bot/duty"
+)
+
+func postPage(callDuty CallDutyFunc, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	Page(callDuty)(recorder, httptest.NewRequest(http.MethodPost, "/api/v1/page", strings.NewReader(body)))
+	return recorder
+}
+
+func TestPage_CallsDuty(t *testing.T) {
+	var call commands.DutyCall
+	recorder := postPage(func(c commands.DutyCall) (*duty.DutyResult, error) {
+		call = c
+		return &duty.DutyResult{DutyID: "alice", IsNewAssignment: true}, nil
+	}, `{"title":"Deploy <failed>","body":"pipeline 42","severity":"warning","chat_id":"ops"}`)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var response pageResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.DutyID != "alice" || !response.IsNewAssignment {
+		t.Errorf("unexpected response %+v", response)
+	}
+	if call.ActorUserId != pageActor || call.ChatId != "ops" {
+		t.Errorf("unexpected call %+v", call)
+	}
+	if call.Details != "⚠️ <b>[WARNING] Deploy &lt;failed&gt;</b>\npipeline 42" {
+		t.Errorf("unexpected details %q", call.Details)
+	}
+}
+
+func TestPage_DefaultsToCritical(t *testing.T) {
+	var call commands.DutyCall
+	postPage(func(c commands.DutyCall) (*duty.DutyResult, error) {
+		call = c
+		return &duty.DutyResult{DutyID: "alice"}, nil
+	}, `{"title":"Portal down"}`)
+
+	if !strings.Contains(call.Details, "[CRITICAL] Portal down") {
+		t.Errorf("expected critical severity, got %q", call.Details)
+	}
+}
+
+func TestPage_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		result *duty.DutyResult
+		err    error
+		want   int
+	}{
+		{"invalid json", "{", nil, nil, http.StatusBadRequest},
+		{"missing title", `{"body":"x"}`, nil, nil, http.StatusBadRequest},
+		{"unknown severity", `{"title":"x","severity":"fatal"}`, nil, nil, http.StatusBadRequest},
+		{"outside working hours", `{"title":"x"}`, nil, commands.ErrOutsideWorkingHours, http.StatusConflict},
+		{"no duty", `{"title":"x"}`, nil, nil, http.StatusNotFound},
+		{"service error", `{"title":"x"}`, nil, errors.New("db down"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := postPage(func(commands.DutyCall) (*duty.DutyResult, error) {
+				return tt.result, tt.err
+			}, tt.body)
+			if recorder.Code != tt.want {
+				t.Errorf("expected status %d, got %d", tt.want, recorder.Code)
+			}
+		})
+	}
+}
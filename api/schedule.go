@@ -0,0 +1,56 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"watch_bot/duty"
+)
+
+// ScheduleFunc returns the projected schedule for the given number of working days
+type ScheduleFunc func(days int) ([]duty.ScheduledDay, error)
+
+type scheduleDay struct {
+	Date           string `json:"date"`
+	Weekday        string `json:"weekday"`
+	DutyID         string `json:"duty_id"`
+	ReplacedDutyID string `json:"replaced_duty_id,omitempty"`
+}
+
+type scheduleResponse struct {
+	Days []scheduleDay `json:"days"`
+}
+
+// Schedule serves GET /api/v1/schedule?days=N with the projected rotation as JSON
+func Schedule(getSchedule ScheduleFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		days := duty.DefaultScheduleDays
+		if value := r.URL.Query().Get("days"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 || parsed > duty.MaxScheduleDays {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("days must be a number from 1 to %d", duty.MaxScheduleDays))
+				return
+			}
+			days = parsed
+		}
+
+		schedule, err := getSchedule(days)
+		if err != nil {
+			log.Printf("failed to get duty schedule: %v", err)
+			writeError(w, http.StatusInternalServerError, "failed to get duty schedule")
+			return
+		}
+
+		response := scheduleResponse{Days: make([]scheduleDay, 0, len(schedule))}
+		for _, day := range schedule {
+			response.Days = append(response.Days, scheduleDay{
+				Date:           day.Date.Format("2006-01-02"),
+				Weekday:        day.Date.Weekday().String(),
+				DutyID:         day.DutyID,
+				ReplacedDutyID: day.ReplacedDutyID,
+			})
+		}
+		writeJSON(w, http.StatusOK, response)
+	}
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"watch_bot/duty"
+)
+
+func TestSchedule_ReturnsProjection(t *testing.T) {
+	requestedDays := 0
+	handler := Schedule(func(days int) ([]duty.ScheduledDay, error) {
+		requestedDays = days
+		return []duty.ScheduledDay{
+			{Date: time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC), DutyID: "alice"},
+			{Date: time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC), DutyID: "charlie", ReplacedDutyID: "bob"},
+		}, nil
+	})
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/schedule?days=2", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	if requestedDays != 2 {
+		t.Errorf("expected 2 days to be requested, got %d", requestedDays)
+	}
+	var response scheduleResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Days) != 2 {
+		t.Fatalf("expected 2 days, got %d", len(response.Days))
+	}
+	if response.Days[0] != (scheduleDay{Date: "2026-01-08", Weekday: "Thursday", DutyID: "alice"}) {
+		t.Errorf("unexpected first day: %+v", response.Days[0])
+	}
+	if response.Days[1].ReplacedDutyID != "bob" {
+		t.Errorf("expected swap to be reported, got %+v", response.Days[1])
+	}
+}
+
+func TestSchedule_DefaultDays(t *testing.T) {
+	requestedDays := 0
+	handler := Schedule(func(days int) ([]duty.ScheduledDay, error) {
+		requestedDays = days
+		return nil, nil
+	})
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/schedule", nil))
+
+	if recorder.Code != http.StatusOK || requestedDays != duty.DefaultScheduleDays {
+		t.Errorf("expected default request to succeed with %d days, got status %d and %d days", duty.DefaultScheduleDays, recorder.Code, requestedDays)
+	}
+	if body := recorder.Body.String(); body != "{\"days\":[]}\n" {
+		t.Errorf("expected empty days list, got %q", body)
+	}
+}
+
+func TestSchedule_InvalidDays(t *testing.T) {
+	handler := Schedule(func(days int) ([]duty.ScheduledDay, error) {
+		t.Fatal("schedule should not be requested")
+		return nil, nil
+	})
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/schedule?days=abc", nil))
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", recorder.Code)
+	}
+}
+
+func TestSchedule_ServiceError(t *testing.T) {
+	handler := Schedule(func(days int) ([]duty.ScheduledDay, error) {
+		return nil, errors.New("db down")
+	})
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/schedule", nil))
+
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", recorder.Code)
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"watch_bot/duty"
+)
+
+// StatsFunc returns the duty statistics of every person over the given number of days
+type StatsFunc func(days int) ([]duty.PersonStats, error)
+
+type personStats struct {
+	DutyID       string `json:"duty_id"`
+	DutyDays     int    `json:"duty_days"`
+	NextSkips    int    `json:"next_skips"`
+	Pages        int    `json:"pages"`
+	OffDayShifts int    `json:"off_day_shifts"`
+}
+
+type statsResponse struct {
+	Days   int           `json:"days"`
+	People []personStats `json:"people"`
+}
+
+// Stats serves GET /api/v1/stats?days=N with the duty load of every person as JSON
+func Stats(getStats StatsFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		days := duty.DefaultStatsDays
+		if value := r.URL.Query().Get("days"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 || parsed > duty.MaxStatsDays {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("days must be a number from 1 to %d", duty.MaxStatsDays))
+				return
+			}
+			days = parsed
+		}
+
+		stats, err := getStats(days)
+		if err != nil {
+			log.Printf("failed to get duty statistics: %v", err)
+			writeError(w, http.StatusInternalServerError, "failed to get duty statistics")
+			return
+		}
+
+		response := statsResponse{Days: days, People: make([]personStats, 0, len(stats))}
+		for _, person := range stats {
+			response.People = append(response.People, personStats{
+				DutyID:       person.DutyID,
+				DutyDays:     person.DutyDays,
+				NextSkips:    person.NextSkips,
+				Pages:        person.Pages,
+				OffDayShifts: person.OffDayShifts,
+			})
+		}
+		writeJSON(w, http.StatusOK, response)
+	}
+}
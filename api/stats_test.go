@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"watch_bot/duty"
+)
+
+func TestStats_ReturnsPeople(t *testing.T) {
+	requestedDays := 0
+	handler := Stats(func(days int) ([]duty.PersonStats, error) {
+		requestedDays = days
+		return []duty.PersonStats{
+			{DutyID: "alice", DutyDays: 5, NextSkips: 1, Pages: 3, OffDayShifts: 2},
+			{DutyID: "bob"},
+		}, nil
+	})
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/stats?days=14", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	if requestedDays != 14 {
+		t.Errorf("expected 14 days to be requested, got %d", requestedDays)
+	}
+	var response statsResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Days != 14 || len(response.People) != 2 {
+		t.Fatalf("unexpected response: %+v", response)
+	}
+	if response.People[0] != (personStats{DutyID: "alice", DutyDays: 5, NextSkips: 1, Pages: 3, OffDayShifts: 2}) {
+		t.Errorf("unexpected first person: %+v", response.People[0])
+	}
+}
+
+func TestStats_DefaultAndInvalidDays(t *testing.T) {
+	requestedDays := 0
+	handler := Stats(func(days int) ([]duty.PersonStats, error) {
+		requestedDays = days
+		return nil, nil
+	})
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/stats", nil))
+	if recorder.Code != http.StatusOK || requestedDays != duty.DefaultStatsDays {
+		t.Errorf("expected default request to succeed with %d days, got status %d and %d days", duty.DefaultStatsDays, recorder.Code, requestedDays)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/stats?days=366", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for a too long period, got %d", recorder.Code)
+	}
+}
+
+func TestStats_ServiceError(t *testing.T) {
+	handler := Stats(func(days int) ([]duty.PersonStats, error) {
+		return nil, errors.New("db down")
+	})
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/stats", nil))
+
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", recorder.Code)
+	}
+}
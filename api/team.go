@@ -0,0 +1,23 @@
+package api
+
+import "net/http"
+
+// TeamQueryParam selects the team of a request, requests without it are served by the default team
+const TeamQueryParam = "team"
+
+// ForTeam dispatches the request to the handler of the team named in the ?team= query parameter.
+// Unknown teams get 404.
+func ForTeam(handlers map[string]http.Handler, defaultTeam string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		team := r.URL.Query().Get(TeamQueryParam)
+		if team == "" {
+			team = defaultTeam
+		}
+		handler, ok := handlers[team]
+		if !ok {
+			writeError(w, http.StatusNotFound, "unknown team")
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}
+}
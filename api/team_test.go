@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestForTeam(t *testing.T) {
+	respond := func(team string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, map[string]string{"team": team})
+		})
+	}
+	handler := ForTeam(map[string]http.Handler{
+		"default":  respond("default"),
+		"payments": respond("payments"),
+	}, "default")
+
+	tests := []struct {
+		name       string
+		url        string
+		wantStatus int
+		wantBody   string
+	}{
+		{"default team", "/api/v1/schedule", http.StatusOK, `{"team":"default"}`},
+		{"named team", "/api/v1/schedule?team=payments", http.StatusOK, `{"team":"payments"}`},
+		{"unknown team", "/api/v1/schedule?team=unknown", http.StatusNotFound, `{"error":"unknown team"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.url, nil))
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, recorder.Code)
+			}
+			if body := recorder.Body.String(); body != tt.wantBody+"\n" {
+				t.Errorf("unexpected body %q", body)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+	"watch_bot/dao"
+	"watch_bot/working_calendar"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const unusualDayLayout = "2006-01-02"
+
+const maxUnusualDayPayload = 4 << 10
+
+// UnusualDayStore reads and changes the unusual days of the working calendar,
+// see working_calendar.UnusualDays
+type UnusualDayStore interface {
+	From(date time.Time) []dao.UnusualDay
+	Add(day dao.UnusualDay) (bool, error)
+	Remove(day time.Time) (bool, error)
+}
+
+// unusualDayJSON is an unusual day in requests and responses, an empty type means dao.UnusualDayInverted.
+// Source is only reported, days added through the API are always entered by hand.
+type unusualDayJSON struct {
+	Date      string `json:"date"`
+	Type      string `json:"type,omitempty"`
+	StartTime string `json:"start_time,omitempty"`
+	EndTime   string `json:"end_time,omitempty"`
+	Source    string `json:"source,omitempty"`
+}
+
+func newUnusualDayJSON(day dao.UnusualDay) unusualDayJSON {
+	return unusualDayJSON{
+		Date:      day.Date.Format(unusualDayLayout),
+		Type:      day.TypeOrDefault(),
+		StartTime: day.StartTime,
+		EndTime:   day.EndTime,
+		Source:    day.Source,
+	}
+}
+
+type unusualDaysResponse struct {
+	Days []unusualDayJSON `json:"days"`
+}
+
+// UnusualDays serves GET /api/v1/unusual-days?from=YYYY-MM-DD with the unusual days from the date, today by default
+func UnusualDays(store UnusualDayStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		from := time.Now()
+		if value := r.URL.Query().Get("from"); value != "" {
+			parsed, err := time.Parse(unusualDayLayout, value)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "from must be a date in YYYY-MM-DD format")
+				return
+			}
+			from = parsed
+		}
+
+		response := unusualDaysResponse{Days: []unusualDayJSON{}}
+		for _, day := range store.From(from) {
+			response.Days = append(response.Days, newUnusualDayJSON(day))
+		}
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
+// AddUnusualDay serves POST /api/v1/unusual-days with a {"date": "YYYY-MM-DD"} body, optionally with
+// "type" and "start_time"/"end_time" in HH:MM. It responds with 201 for a new unusual day
+// and 200 when the date already was one, in which case its type and hours are replaced.
+func AddUnusualDay(store UnusualDayStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request unusualDayJSON
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxUnusualDayPayload)).Decode(&request); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON body")
+			return
+		}
+		date, err := time.Parse(unusualDayLayout, request.Date)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "date must be in YYYY-MM-DD format")
+			return
+		}
+
+		day := dao.UnusualDay{Date: date, Type: request.Type, StartTime: request.StartTime, EndTime: request.EndTime}
+		if err := working_calendar.ValidateUnusualDay(day); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		added, err := store.Add(day)
+		if err != nil {
+			log.Printf("failed to add unusual day %s: %v", request.Date, err)
+			writeError(w, http.StatusInternalServerError, "failed to add unusual day")
+			return
+		}
+		status := http.StatusOK
+		if added {
+			status = http.StatusCreated
+		}
+		writeJSON(w, status, newUnusualDayJSON(day))
+	}
+}
+
+// RemoveUnusualDay serves DELETE /api/v1/unusual-days/{date}
+func RemoveUnusualDay(store UnusualDayStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		date, err := time.Parse(unusualDayLayout, chi.URLParam(r, "date"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "date must be in YYYY-MM-DD format")
+			return
+		}
+
+		removed, err := store.Remove(date)
+		if err != nil {
+			log.Printf("failed to remove unusual day %s: %v", date.Format(unusualDayLayout), err)
+			writeError(w, http.StatusInternalServerError, "failed to remove unusual day")
+			return
+		}
+		if !removed {
+			writeError(w, http.StatusNotFound, "not an unusual day")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"watch_bot/dao"
+	"watch_bot/working_calendar"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func newUnusualDaysRouter(store UnusualDayStore) http.Handler {
+	router := chi.NewRouter()
+	router.Get("/api/v1/unusual-days", UnusualDays(store))
+	router.Post("/api/v1/unusual-days", AddUnusualDay(store))
+	router.Delete("/api/v1/unusual-days/{date}", RemoveUnusualDay(store))
+	return router
+}
+
+func TestUnusualDays_AddListRemove(t *testing.T) {
+	router := newUnusualDaysRouter(working_calendar.NewUnusualDays(dao.NewMemoryRepository()))
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return recorder
+	}
+
+	if recorder := serve(http.MethodPost, "/api/v1/unusual-days", `{"date":"2030-05-01"}`); recorder.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if recorder := serve(http.MethodPost, "/api/v1/unusual-days", `{"date":"2030-05-01"}`); recorder.Code != http.StatusOK {
+		t.Errorf("expected status 200 for an existing day, got %d", recorder.Code)
+	}
+	if body := serve(http.MethodGet, "/api/v1/unusual-days?from=2030-01-01", "").Body.String(); body != "{\"days\":[{\"date\":\"2030-05-01\",\"type\":\"inverted\"}]}\n" {
+		t.Errorf("unexpected list %q", body)
+	}
+	if recorder := serve(http.MethodDelete, "/api/v1/unusual-days/2030-05-01", ""); recorder.Code != http.StatusNoContent {
+		t.Errorf("expected status 204, got %d", recorder.Code)
+	}
+	if recorder := serve(http.MethodDelete, "/api/v1/unusual-days/2030-05-01", ""); recorder.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for a missing day, got %d", recorder.Code)
+	}
+	if body := serve(http.MethodGet, "/api/v1/unusual-days?from=2030-01-01", "").Body.String(); body != "{\"days\":[]}\n" {
+		t.Errorf("expected an empty list, got %q", body)
+	}
+}
+
+func TestUnusualDays_Typed(t *testing.T) {
+	router := newUnusualDaysRouter(working_calendar.NewUnusualDays(dao.NewMemoryRepository()))
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return recorder
+	}
+
+	recorder := serve(http.MethodPost, "/api/v1/unusual-days", `{"date":"2030-05-04","type":"working","start_time":"10:00","end_time":"14:00"}`)
+	if recorder.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	recorder = serve(http.MethodPost, "/api/v1/unusual-days", `{"date":"2030-05-04","type":"shortened","end_time":"15:00"}`)
+	if recorder.Code != http.StatusOK {
+		t.Errorf("expected status 200 when the type changes, got %d", recorder.Code)
+	}
+	want := "{\"days\":[{\"date\":\"2030-05-04\",\"type\":\"shortened\",\"end_time\":\"15:00\"}]}\n"
+	if body := serve(http.MethodGet, "/api/v1/unusual-days?from=2030-01-01", "").Body.String(); body != want {
+		t.Errorf("unexpected list %q", body)
+	}
+}
+
+func TestUnusualDays_ImportedDayTakenOverByHand(t *testing.T) {
+	store := working_calendar.NewUnusualDays(dao.NewMemoryRepository())
+	holiday := dao.UnusualDay{Date: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), Type: dao.UnusualDayHoliday, Source: "example.com"}
+	if _, err := store.Import([]dao.UnusualDay{holiday}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	router := newUnusualDaysRouter(store)
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return recorder
+	}
+
+	want := "{\"days\":[{\"date\":\"2030-01-01\",\"type\":\"holiday\",\"source\":\"example.com\"}]}\n"
+	if body := serve(http.MethodGet, "/api/v1/unusual-days?from=2030-01-01", "").Body.String(); body != want {
+		t.Errorf("unexpected list %q", body)
+	}
+	serve(http.MethodPost, "/api/v1/unusual-days", `{"date":"2030-01-01","type":"working","source":"example.com"}`)
+	want = "{\"days\":[{\"date\":\"2030-01-01\",\"type\":\"working\"}]}\n"
+	if body := serve(http.MethodGet, "/api/v1/unusual-days?from=2030-01-01", "").Body.String(); body != want {
+		t.Errorf("expected the day to become a manual one, got %q", body)
+	}
+	if summary, _ := store.Import([]dao.UnusualDay{holiday}); summary.Skipped != 1 {
+		t.Errorf("expected the next import to keep the manual day, got %+v", summary)
+	}
+}
+
+func TestUnusualDays_InvalidDates(t *testing.T) {
+	router := newUnusualDaysRouter(working_calendar.NewUnusualDays(dao.NewMemoryRepository()))
+
+	for _, request := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/api/v1/unusual-days?from=tomorrow", nil),
+		httptest.NewRequest(http.MethodPost, "/api/v1/unusual-days", strings.NewReader(`{"date":"01.05.2030"}`)),
+		httptest.NewRequest(http.MethodPost, "/api/v1/unusual-days", strings.NewReader(`not json`)),
+		httptest.NewRequest(http.MethodPost, "/api/v1/unusual-days", strings.NewReader(`{"date":"2030-05-01","type":"vacation"}`)),
+		httptest.NewRequest(http.MethodPost, "/api/v1/unusual-days", strings.NewReader(`{"date":"2030-05-01","type":"holiday","end_time":"15:00"}`)),
+		httptest.NewRequest(http.MethodPost, "/api/v1/unusual-days", strings.NewReader(`{"date":"2030-05-01","type":"working","start_time":"9am"}`)),
+		httptest.NewRequest(http.MethodDelete, "/api/v1/unusual-days/may", nil),
+	} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("%s %s: expected status 400, got %d", request.Method, request.URL, recorder.Code)
+		}
+	}
+}
+
+type failingUnusualDayStore struct{}
+
+func (failingUnusualDayStore) From(time.Time) []dao.UnusualDay  { return nil }
+func (failingUnusualDayStore) Add(dao.UnusualDay) (bool, error) { return false, errors.New("db down") }
+func (failingUnusualDayStore) Remove(time.Time) (bool, error)   { return false, errors.New("db down") }
+
+func TestUnusualDays_StoreError(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	newUnusualDaysRouter(failingUnusualDayStore{}).ServeHTTP(recorder,
+		httptest.NewRequest(http.MethodPost, "/api/v1/unusual-days", strings.NewReader(`{"date":"2030-05-01"}`)))
+
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", recorder.Code)
+	}
+}
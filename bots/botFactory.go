@@ -13,10 +13,12 @@ func CreateBot(ctx context.Context, settings BotSettings) WatchBot {
 		bot.(*VkTeamsBot).BotApiUrl = settings.BotApiUrl
 		bot.(*VkTeamsBot).MainChatId = settings.MainChatId
 		bot.(*VkTeamsBot).SupportChatId = settings.SupportChatId
+		bot.(*VkTeamsBot).TeamChatIds = settings.TeamChatIds
 	case "telegram":
 		bot = &TelegramBot{}
 		bot.(*TelegramBot).MainChatId = settings.MainChatId
 		bot.(*TelegramBot).SupportChatId = settings.SupportChatId
+		bot.(*TelegramBot).TeamChatIds = settings.TeamChatIds
 	default:
 		log.Fatal("unsupported bot type")
 	}
@@ -28,6 +30,7 @@ type BotSettings struct {
 	BotApiUrl       string
 	MainChatId      string
 	SupportChatId   string
+	TeamChatIds     []string // main and support chats of the teams other than the default one
 	BotType         string
 	MessagesChannel chan Message
 	CommandsChannel chan Command
@@ -39,6 +42,13 @@ type Message struct {
 	ChatId    string
 	Text      string
 	ParseMode string // "HTML" or "MarkdownV2" for VK Teams
+	Buttons   []Button
+}
+
+// Button is an inline button under a message, pressing it runs Command as if the user typed it
+type Button struct {
+	Text    string
+	Command string
 }
 
 type Command struct {
@@ -47,3 +57,15 @@ type Command struct {
 	UserId string
 	Params map[string]string
 }
+
+// SendNonBlocking puts a message into the channel without waiting when the buffer is full
+func SendNonBlocking(messagesChan chan Message, message Message) {
+	if messagesChan == nil {
+		return
+	}
+	select {
+	case messagesChan <- message:
+	default:
+		log.Printf("Warning: failed to send notification to %s: channel buffer full", message.ChatId)
+	}
+}
@@ -13,36 +13,49 @@ type CommandHandler interface {
 	Description() string
 }
 
+// chatRestrictedHandler resolves the handler from the chat of the command.
+// Every team registers its own handler for its chats, the default handler serves any chat.
 type chatRestrictedHandler struct {
-	handler        CommandHandler
-	allowedChatIds map[string]struct{}
+	description    string
+	defaultHandler CommandHandler
+	chatHandlers   map[string]CommandHandler
 }
 
 func NewChatRestrictedHandler(handler CommandHandler, allowedChatIds ...string) CommandHandler {
-	allowed := make(map[string]struct{})
-	for _, chatId := range allowedChatIds {
+	h := &chatRestrictedHandler{
+		description:  handler.Description(),
+		chatHandlers: make(map[string]CommandHandler),
+	}
+	h.add(handler, allowedChatIds...)
+	return h
+}
+
+// add routes the chats to the handler, without chats the handler serves any chat
+func (h *chatRestrictedHandler) add(handler CommandHandler, chatIds ...string) {
+	restricted := false
+	for _, chatId := range chatIds {
 		if chatId != "" {
-			allowed[chatId] = struct{}{}
+			h.chatHandlers[chatId] = handler
+			restricted = true
 		}
 	}
-	return &chatRestrictedHandler{
-		handler:        handler,
-		allowedChatIds: allowed,
+	if !restricted {
+		h.defaultHandler = handler
 	}
 }
 
 func (h *chatRestrictedHandler) Execute(cmd Command) (string, error) {
-	if len(h.allowedChatIds) == 0 {
-		return h.handler.Execute(cmd)
+	if handler, ok := h.chatHandlers[cmd.ChatId]; ok {
+		return handler.Execute(cmd)
 	}
-	if _, ok := h.allowedChatIds[cmd.ChatId]; !ok {
+	if h.defaultHandler == nil {
 		return "This command is not allowed in this chat", nil
 	}
-	return h.handler.Execute(cmd)
+	return h.defaultHandler.Execute(cmd)
 }
 
 func (h *chatRestrictedHandler) Description() string {
-	return h.handler.Description()
+	return h.description
 }
 
 // CommandRouter routes commands to appropriate handlers
@@ -62,6 +75,16 @@ func (r *CommandRouter) Register(name string, handler CommandHandler) {
 	r.handlers[name] = handler
 }
 
+// RegisterForChats adds a command handler that serves only the given chats.
+// Handlers registered under the same name for other chats are kept, so every team can have its own instance.
+func (r *CommandRouter) RegisterForChats(name string, handler CommandHandler, chatIds ...string) {
+	if restricted, ok := r.handlers[name].(*chatRestrictedHandler); ok {
+		restricted.add(handler, chatIds...)
+		return
+	}
+	r.handlers[name] = NewChatRestrictedHandler(handler, chatIds...)
+}
+
 // GetRegisteredCommands returns list of registered command names with descriptions
 func (r *CommandRouter) GetRegisteredCommands() map[string]string {
 	commands := make(map[string]string)
@@ -145,6 +168,23 @@ func ParseCommand(text string, chatId string, userId ...string) *Command {
 	return cmd
 }
 
+// privateChatCommands are accepted in private chats with the bot, so that a paged person
+// can acknowledge the call in their direct messages. Other commands need a configured chat.
+var privateChatCommands = map[string]bool{"ack": true}
+
+// commandFromChat parses the text into a command when the chat may send it, otherwise it returns nil
+func commandFromChat(text string, chatId string, userId string, isPrivate bool, allowedChatIds ...string) *Command {
+	cmd := ParseCommand(text, chatId, userId)
+	if cmd == nil {
+		return nil
+	}
+	if !isAllowedCommandChat(chatId, allowedChatIds...) && !(isPrivate && privateChatCommands[cmd.Name]) {
+		log.Printf("Ignoring command %s from chat %s (not allowed command chat)", cmd.Name, chatId)
+		return nil
+	}
+	return cmd
+}
+
 func isAllowedCommandChat(chatId string, allowedChatIds ...string) bool {
 	hasAllowedChatIds := false
 	for _, allowedChatId := range allowedChatIds {
@@ -207,6 +207,49 @@ func TestChatRestrictedHandler_RejectsOtherChat(t *testing.T) {
 	}
 }
 
+func TestCommandRouter_RegisterForChats(t *testing.T) {
+	router := NewCommandRouter()
+	router.RegisterForChats("duty", &mockHandler{response: "team a"}, "main-a")
+	router.RegisterForChats("duty", &mockHandler{response: "team b"}, "main-b", "support-b")
+
+	tests := []struct {
+		chatId   string
+		expected string
+	}{
+		{"main-a", "team a"},
+		{"main-b", "team b"},
+		{"support-b", "team b"},
+		{"other", "This command is not allowed in this chat"},
+	}
+	for _, tt := range tests {
+		response, err := router.Handle(Command{Name: "duty", ChatId: tt.chatId})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if response != tt.expected {
+			t.Errorf("chat %s: expected %q, got %q", tt.chatId, tt.expected, response)
+		}
+	}
+	if len(router.GetRegisteredCommands()) != 1 {
+		t.Errorf("expected a single registered command, got %v", router.GetRegisteredCommands())
+	}
+}
+
+func TestCommandRouter_RegisterForChatsKeepsUnrestrictedDefault(t *testing.T) {
+	router := NewCommandRouter()
+	router.RegisterForChats("duty", &mockHandler{response: "default"})
+	router.RegisterForChats("duty", &mockHandler{response: "team b"}, "main-b")
+
+	response, _ := router.Handle(Command{Name: "duty", ChatId: "main-b"})
+	if response != "team b" {
+		t.Errorf("expected team b handler, got %q", response)
+	}
+	response, _ = router.Handle(Command{Name: "duty", ChatId: "other"})
+	if response != "default" {
+		t.Errorf("expected default handler, got %q", response)
+	}
+}
+
 func TestIsAllowedCommandChat(t *testing.T) {
 	if !isAllowedCommandChat("main", "main", "support") {
 		t.Fatal("expected main chat to be allowed")
@@ -221,3 +264,32 @@ func TestIsAllowedCommandChat(t *testing.T) {
 		t.Fatal("expected chat to be allowed when no allow list is configured")
 	}
 }
+
+func TestCommandFromChat(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		chatId    string
+		isPrivate bool
+		want      string
+	}{
+		{"configured chat", "\\next", "support", false, "next"},
+		{"ack in private chat", "\\ack 3", "dm", true, "ack"},
+		{"duty in private chat", "\\duty", "dm", true, ""},
+		{"next in private chat", "\\next", "dm", true, ""},
+		{"ack in other group", "\\ack", "group", false, ""},
+		{"text in configured chat", "hello", "support", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := commandFromChat(tt.text, tt.chatId, "42", tt.isPrivate, "main", "support")
+			got := ""
+			if cmd != nil {
+				got = cmd.Name
+			}
+			if got != tt.want {
+				t.Errorf("expected command %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"watch_bot/bots"
+	"watch_bot/dao"
+	"watch_bot/duty"
+)
+
+const dateLayout = "2006-01-02"
+
+// AbsenceCommandConfig contains configuration for the away and back commands
+type AbsenceCommandConfig struct {
+	Repository dao.DutyRepository
+	Location   *time.Location
+}
+
+type absenceServicer interface {
+	AddAbsence(userId string, from, to time.Time, reason string) (*dao.Absence, error)
+	EndAbsence(userId string) (int64, error)
+}
+
+// AwayCommand handles the \away <from> <to> [reason] command
+type AwayCommand struct {
+	dutyService absenceServicer
+}
+
+// NewAwayCommand creates a new AwayCommand
+func NewAwayCommand(config AbsenceCommandConfig) *AwayCommand {
+	return &AwayCommand{
+		dutyService: duty.NewService(config.Repository).WithLocation(config.Location),
+	}
+}
+
+// Execute registers an absence period for the calling user
+func (a *AwayCommand) Execute(cmd bots.Command) (string, error) {
+	usage := "Usage: \\away <from> <to> [reason], dates in YYYY-MM-DD format"
+	from, errFrom := time.Parse(dateLayout, cmd.Params["0"])
+	to, errTo := time.Parse(dateLayout, cmd.Params["1"])
+	if errFrom != nil || errTo != nil {
+		return usage, nil
+	}
+	if to.Before(from) {
+		return "The end of the absence must not be before its start", nil
+	}
+
+	absence, err := a.dutyService.AddAbsence(cmd.UserId, from, to, joinParamsFrom(cmd.Params, 2))
+	if errors.Is(err, duty.ErrNotInRotation) {
+		return "You are not in the duty rotation", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to add absence: %w", err)
+	}
+
+	return fmt.Sprintf("Away from %s to %s, the rotation will skip you", absence.From.Format(dateLayout), absence.To.Format(dateLayout)), nil
+}
+
+// Description returns command description
+func (a *AwayCommand) Description() string {
+	return "mark yourself away: \\away <from> <to> [reason]"
+}
+
+// BackCommand handles the \back command
+type BackCommand struct {
+	dutyService absenceServicer
+}
+
+// NewBackCommand creates a new BackCommand
+func NewBackCommand(config AbsenceCommandConfig) *BackCommand {
+	return &BackCommand{
+		dutyService: duty.NewService(config.Repository).WithLocation(config.Location),
+	}
+}
+
+// Execute cancels the current and future absences of the calling user
+func (b *BackCommand) Execute(cmd bots.Command) (string, error) {
+	changed, err := b.dutyService.EndAbsence(cmd.UserId)
+	if errors.Is(err, duty.ErrNotInRotation) {
+		return "You are not in the duty rotation", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to end absence: %w", err)
+	}
+	if changed == 0 {
+		return "You have no current or planned absences", nil
+	}
+	return "Welcome back! You are in the rotation again", nil
+}
+
+// Description returns command description
+func (b *BackCommand) Description() string {
+	return "cancel your current and planned absences"
+}
+
+// joinParamsFrom joins positional params starting from index start with spaces
+func joinParamsFrom(params map[string]string, start int) string {
+	var parts []string
+	for i := start; ; i++ {
+		value, ok := params[strconv.Itoa(i)]
+		if !ok {
+			break
+		}
+		parts = append(parts, value)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"watch_bot/bots"
+	"watch_bot/dao"
+	"watch_bot/duty"
+)
+
+type mockAbsenceService struct {
+	userId  string
+	from    time.Time
+	to      time.Time
+	reason  string
+	changed int64
+	err     error
+}
+
+func (m *mockAbsenceService) AddAbsence(userId string, from, to time.Time, reason string) (*dao.Absence, error) {
+	m.userId, m.from, m.to, m.reason = userId, from, to, reason
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &dao.Absence{From: from, To: to, Reason: reason}, nil
+}
+
+func (m *mockAbsenceService) EndAbsence(userId string) (int64, error) {
+	m.userId = userId
+	return m.changed, m.err
+}
+
+func TestAwayCommand_Execute_RegistersAbsence(t *testing.T) {
+	service := &mockAbsenceService{}
+	cmd := &AwayCommand{dutyService: service}
+
+	response, err := cmd.Execute(bots.Command{
+		Name:   "away",
+		UserId: "alice",
+		Params: map[string]string{"0": "2026-02-02", "1": "2026-02-06", "2": "winter", "3": "vacation"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if service.userId != "alice" || service.reason != "winter vacation" {
+		t.Errorf("unexpected absence registered: %+v", service)
+	}
+	if !strings.Contains(response, "2026-02-02") || !strings.Contains(response, "2026-02-06") {
+		t.Errorf("expected response to mention the period, got %q", response)
+	}
+}
+
+func TestAwayCommand_Execute_InvalidDates(t *testing.T) {
+	tests := []map[string]string{
+		{},
+		{"0": "2026-02-02"},
+		{"0": "02.02.2026", "1": "06.02.2026"},
+	}
+	for _, params := range tests {
+		cmd := &AwayCommand{dutyService: &mockAbsenceService{}}
+		response, err := cmd.Execute(bots.Command{Name: "away", UserId: "alice", Params: params})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.HasPrefix(response, "Usage:") {
+			t.Errorf("expected usage response for %v, got %q", params, response)
+		}
+	}
+}
+
+func TestAwayCommand_Execute_EndBeforeStart(t *testing.T) {
+	service := &mockAbsenceService{}
+	cmd := &AwayCommand{dutyService: service}
+
+	response, _ := cmd.Execute(bots.Command{Name: "away", UserId: "alice", Params: map[string]string{"0": "2026-02-06", "1": "2026-02-02"}})
+	if response != "The end of the absence must not be before its start" {
+		t.Errorf("unexpected response: %q", response)
+	}
+	if service.userId != "" {
+		t.Error("expected no absence to be registered")
+	}
+}
+
+func TestAwayCommand_Execute_NotInRotation(t *testing.T) {
+	cmd := &AwayCommand{dutyService: &mockAbsenceService{err: duty.ErrNotInRotation}}
+
+	response, err := cmd.Execute(bots.Command{Name: "away", UserId: "stranger", Params: map[string]string{"0": "2026-02-02", "1": "2026-02-06"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response != "You are not in the duty rotation" {
+		t.Errorf("unexpected response: %q", response)
+	}
+}
+
+func TestBackCommand_Execute(t *testing.T) {
+	service := &mockAbsenceService{changed: 1}
+	cmd := &BackCommand{dutyService: service}
+
+	response, err := cmd.Execute(bots.Command{Name: "back", UserId: "alice"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if service.userId != "alice" {
+		t.Errorf("expected absence of alice to be ended, got %q", service.userId)
+	}
+	if response != "Welcome back! You are in the rotation again" {
+		t.Errorf("unexpected response: %q", response)
+	}
+}
+
+func TestBackCommand_Execute_NothingToEnd(t *testing.T) {
+	cmd := &BackCommand{dutyService: &mockAbsenceService{}}
+
+	response, err := cmd.Execute(bots.Command{Name: "back", UserId: "alice"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response != "You have no current or planned absences" {
+		t.Errorf("unexpected response: %q", response)
+	}
+}
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"strconv"
+	"watch_bot/bots"
+	"watch_bot/escalation"
+)
+
+// AckCommandConfig contains configuration for the ack command
+type AckCommandConfig struct {
+	// Trackers of all teams, a call is acknowledged in whichever tracker holds it
+	Trackers []*escalation.Tracker
+}
+
+type acknowledger interface {
+	Acknowledge(userId string, callID int64) int
+}
+
+// AckCommand handles the \ack [id] command and the acknowledge button
+type AckCommand struct {
+	trackers []acknowledger
+}
+
+// NewAckCommand creates a new AckCommand
+func NewAckCommand(config AckCommandConfig) *AckCommand {
+	trackers := make([]acknowledger, 0, len(config.Trackers))
+	for _, tracker := range config.Trackers {
+		trackers = append(trackers, tracker)
+	}
+	return &AckCommand{
+		trackers: trackers,
+	}
+}
+
+// Execute acknowledges the duty calls of the sender, or only the given one
+func (a *AckCommand) Execute(cmd bots.Command) (string, error) {
+	var callID int64
+	if value, ok := cmd.Params["0"]; ok {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed < 1 {
+			return "Usage: \\ack [id]", nil
+		}
+		callID = parsed
+	}
+
+	acknowledged := 0
+	for _, tracker := range a.trackers {
+		acknowledged += tracker.Acknowledge(cmd.UserId, callID)
+	}
+	if acknowledged == 0 {
+		return "There is no duty call waiting for your acknowledgement", nil
+	}
+	return "Acknowledged, thank you!", nil
+}
+
+// Description returns command description
+func (a *AckCommand) Description() string {
+	return "acknowledge a duty call"
+}
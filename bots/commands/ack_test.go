@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"testing"
+	"watch_bot/bots"
+)
+
+type mockAcknowledger struct {
+	userId string
+	callID int64
+	result int
+}
+
+func (m *mockAcknowledger) Acknowledge(userId string, callID int64) int {
+	m.userId = userId
+	m.callID = callID
+	return m.result
+}
+
+func TestAckCommand_Execute(t *testing.T) {
+	tests := []struct {
+		name       string
+		params     map[string]string
+		result     int
+		wantCallID int64
+		want       string
+	}{
+		{"all calls", map[string]string{}, 2, 0, "Acknowledged, thank you!"},
+		{"single call from button", map[string]string{"0": "7"}, 1, 7, "Acknowledged, thank you!"},
+		{"nothing pending", map[string]string{}, 0, 0, "There is no duty call waiting for your acknowledgement"},
+		{"invalid id", map[string]string{"0": "x"}, 0, 0, "Usage: \\ack [id]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := &mockAcknowledger{result: tt.result}
+			cmd := &AckCommand{trackers: []acknowledger{tracker}}
+
+			response, err := cmd.Execute(bots.Command{Name: "ack", ChatId: "alice", UserId: "alice", Params: tt.params})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if response != tt.want {
+				t.Fatalf("unexpected response: %q", response)
+			}
+			if tt.want != "Usage: \\ack [id]" && (tracker.userId != "alice" || tracker.callID != tt.wantCallID) {
+				t.Errorf("unexpected acknowledgement %q/%d", tracker.userId, tracker.callID)
+			}
+		})
+	}
+}
@@ -1,25 +1,42 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 	"watch_bot/bots"
+	"watch_bot/dao"
 	"watch_bot/duty"
+	"watch_bot/escalation"
 
 	botgolang "github.com/mail-ru-im/bot-golang"
 )
 
 // DutyCommandConfig contains configuration for the duty command
 type DutyCommandConfig struct {
-	ConnectionStr string
+	Repository    dao.DutyRepository
 	MessagesChan  chan bots.Message
 	SupportChatId string
 	IsWorkingNow  func() bool
+	// Rotation sets the shift length, the zero value hands the duty over every day
+	Rotation duty.Rotation
+	// Location is the time zone in which the duty day starts, nil means the local time zone
+	Location *time.Location
+	// Escalation waits for the duty person to acknowledge the call, nil disables acknowledgements
+	Escalation *escalation.Tracker
 }
 
 // dutyServicer is the interface for retrieving current duty information
 type dutyServicer interface {
-	GetCurrentDuty() (*duty.DutyResult, error)
+	GetCurrentDuty(actorUserId string) (*duty.DutyResult, error)
+	RecordPage(dutyID, actorUserId, role string) error
+}
+
+// callTracker is the interface for waiting for a duty call to be acknowledged
+type callTracker interface {
+	Start(dutyID, details string) int64
 }
 
 // DutyCommand handles the \duty command
@@ -28,65 +45,132 @@ type DutyCommand struct {
 	messagesChan  chan bots.Message
 	supportChatId string
 	isWorkingNow  func() bool
+	tracker       callTracker
 }
 
 // NewDutyCommand creates a new DutyCommand
 func NewDutyCommand(config DutyCommandConfig) *DutyCommand {
-	return &DutyCommand{
-		dutyService:   duty.NewService(config.ConnectionStr),
+	command := &DutyCommand{
+		dutyService:   duty.NewService(config.Repository).WithRotation(config.Rotation).WithLocation(config.Location),
 		messagesChan:  config.MessagesChan,
 		supportChatId: config.SupportChatId,
 		isWorkingNow:  config.IsWorkingNow,
 	}
+	if config.Escalation != nil {
+		command.tracker = config.Escalation
+	}
+	return command
+}
+
+// ErrOutsideWorkingHours is returned by Call when the duty person is called outside working hours
+var ErrOutsideWorkingHours = errors.New("duty can only be called during working hours")
+
+// DutyCall describes a request to call the current duty person
+type DutyCall struct {
+	ActorUserId string
+	// Details is appended to the notifications; a call with details is always announced in the chat
+	Details string
+	// ChatId overrides the support chat for the announcement
+	ChatId string
+	// Secondary pages today's backup duty person instead of the primary one
+	Secondary bool
 }
 
-// Execute handles the duty command
+// Execute handles the duty command, \duty secondary pages the backup duty person
 func (d *DutyCommand) Execute(cmd bots.Command) (string, error) {
-	if d.isWorkingNow != nil && !d.isWorkingNow() {
+	secondary := strings.EqualFold(cmd.Params["0"], dao.RoleSecondary)
+	result, err := d.Call(DutyCall{ActorUserId: cmd.UserId, Secondary: secondary})
+	if errors.Is(err, ErrOutsideWorkingHours) {
 		return "Duty can only be called during working hours", nil
 	}
-
-	result, err := d.dutyService.GetCurrentDuty()
 	if err != nil {
-		return "", fmt.Errorf("failed to get current duty: %w", err)
+		return "", err
+	}
+	if result == nil && secondary {
+		return "No backup duty person available for today", nil
 	}
 	if result == nil {
 		return "No duty assigned for today", nil
 	}
+	return "The development team is rushing to help!", nil
+}
+
+// Call selects the current duty person and notifies them and the support chat.
+// It returns nil without an error when nobody is on duty today in the requested role.
+func (d *DutyCommand) Call(call DutyCall) (*duty.DutyResult, error) {
+	if d.isWorkingNow != nil && !d.isWorkingNow() {
+		return nil, ErrOutsideWorkingHours
+	}
+
+	result, err := d.dutyService.GetCurrentDuty(call.ActorUserId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current duty: %w", err)
+	}
+	if result == nil || (call.Secondary && result.SecondaryDutyID == "") {
+		return nil, nil
+	}
+
+	target := result.DutyID
+	role := dao.RolePrimary
+	dutyText := "You are on duty today!"
+	supportText := fmt.Sprintf("⚠️ Duty person called!\n\nOn duty today: @[%s]", result.DutyID)
+	if result.SecondaryDutyID != "" {
+		supportText += fmt.Sprintf("\nBackup: @[%s]", result.SecondaryDutyID)
+	}
+	if call.Secondary {
+		target = result.SecondaryDutyID
+		role = dao.RoleSecondary
+		dutyText = "You are paged as today's backup duty person!"
+		supportText = fmt.Sprintf("⚠️ Backup duty person called!\n\nBackup on duty today: @[%s]\nPrimary: @[%s]",
+			result.SecondaryDutyID, result.DutyID)
+	}
+
+	// A failed record only skews the statistics, the call itself must go through
+	if err := d.dutyService.RecordPage(target, call.ActorUserId, role); err != nil {
+		log.Printf("failed to record page of %s: %v", target, err)
+	}
+
+	details := ""
+	if call.Details != "" {
+		details = "\n\n" + call.Details
+	}
 
 	// Send notification to the duty person via channel (non-blocking)
 	if d.messagesChan != nil {
-		select {
-		case d.messagesChan <- bots.Message{
-			ChatId: result.DutyID,
-			Text:   "You are on duty today!",
-		}:
-			// Message sent successfully
-		default:
-			log.Printf("Warning: failed to send notification to duty person %s: channel buffer full", result.DutyID)
+		dutyMessage := bots.Message{
+			ChatId: target,
+			Text:   dutyText + details,
+		}
+		if details != "" {
+			dutyMessage.ParseMode = string(botgolang.ParseModeHTML)
 		}
+		if d.tracker != nil {
+			callID := d.tracker.Start(target, call.Details)
+			dutyMessage.Text += "\n\nReply \\ack or press the button to acknowledge"
+			dutyMessage.Buttons = []bots.Button{escalation.AckButton(callID)}
+		}
+		bots.SendNonBlocking(d.messagesChan, dutyMessage)
 
-		// Send notification to support chat about who is on duty (only on first assignment of the day)
-		if d.supportChatId != "" && result.IsNewAssignment {
+		// Send notification to support chat about who is on duty
+		// (on first assignment of the day, with call details or when the backup is paged)
+		chatId := d.supportChatId
+		if call.ChatId != "" {
+			chatId = call.ChatId
+		}
+		if chatId != "" && (result.IsNewAssignment || call.Details != "" || call.Secondary) {
 			// Use @[userId] format for mentions in VK Teams with HTML ParseMode
-			notificationText := fmt.Sprintf("⚠️ Duty person called!\n\nOn duty today: @[%s]", result.DutyID)
-			select {
-			case d.messagesChan <- bots.Message{
-				ChatId:    d.supportChatId,
-				Text:      notificationText,
+			bots.SendNonBlocking(d.messagesChan, bots.Message{
+				ChatId:    chatId,
+				Text:      supportText + details,
 				ParseMode: string(botgolang.ParseModeHTML),
-			}:
-				// Message sent successfully
-			default:
-				log.Printf("Warning: failed to send notification to support chat %s: channel buffer full", d.supportChatId)
-			}
+			})
 		}
 	}
 
-	return "The development team is rushing to help!", nil
+	return result, nil
 }
 
 // Description returns command description
 func (d *DutyCommand) Description() string {
-	return "show current duty person"
+	return "show current duty person, \\duty secondary pages the backup"
 }
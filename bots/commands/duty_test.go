@@ -1,9 +1,11 @@
 package commands
 
 import (
+	"errors"
 	"strings"
 	"testing"
 	"watch_bot/bots"
+	"watch_bot/dao"
 	"watch_bot/duty"
 )
 
@@ -11,15 +13,21 @@ import (
 type mockDutyService struct {
 	result *duty.DutyResult
 	err    error
+	pages  []string
 }
 
-func (m *mockDutyService) GetCurrentDuty() (*duty.DutyResult, error) {
+func (m *mockDutyService) GetCurrentDuty(actorUserId string) (*duty.DutyResult, error) {
 	return m.result, m.err
 }
 
+func (m *mockDutyService) RecordPage(dutyID, actorUserId, role string) error {
+	m.pages = append(m.pages, dutyID+"/"+role)
+	return nil
+}
+
 func TestDutyCommand_Description(t *testing.T) {
 	cmd := NewDutyCommand(DutyCommandConfig{
-		ConnectionStr: "",
+		Repository:    dao.NewMemoryRepository(),
 		MessagesChan:  nil,
 		SupportChatId: "",
 	})
@@ -30,12 +38,18 @@ func TestDutyCommand_Description(t *testing.T) {
 }
 
 func TestDutyCommand_Execute_NoConnection(t *testing.T) {
+	db, err := dao.OpenDB("invalid_connection_string", dao.PoolConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer db.Close()
+
 	cmd := NewDutyCommand(DutyCommandConfig{
-		ConnectionStr: "invalid_connection_string",
+		Repository:    dao.NewPostgresRepository(db),
 		MessagesChan:  nil,
 		SupportChatId: "",
 	})
-	_, err := cmd.Execute(bots.Command{
+	_, err = cmd.Execute(bots.Command{
 		Name:   "duty",
 		ChatId: "123",
 		Params: map[string]string{},
@@ -167,3 +181,156 @@ func TestDutyCommand_Execute_AllowsDuringWorkingHours(t *testing.T) {
 		t.Fatalf("expected 2 outgoing messages during working hours, got %d", len(messagesChan))
 	}
 }
+
+func TestDutyCommand_Call_WithDetailsAnnouncesInTargetChat(t *testing.T) {
+	messagesChan := make(chan bots.Message, 10)
+	cmd := &DutyCommand{
+		dutyService:   &mockDutyService{result: &duty.DutyResult{DutyID: "johndoe", IsNewAssignment: false}},
+		messagesChan:  messagesChan,
+		supportChatId: "support-123",
+	}
+
+	result, err := cmd.Call(DutyCall{ActorUserId: "api", Details: "<b>Deploy failed</b>", ChatId: "ops"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result == nil || result.DutyID != "johndoe" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	close(messagesChan)
+	var recipients []string
+	for msg := range messagesChan {
+		recipients = append(recipients, msg.ChatId)
+		if !strings.Contains(msg.Text, "Deploy failed") || msg.ParseMode != "HTML" {
+			t.Errorf("expected HTML message with details, got %+v", msg)
+		}
+	}
+	if strings.Join(recipients, ",") != "johndoe,ops" {
+		t.Fatalf("expected messages to johndoe and ops, got %v", recipients)
+	}
+}
+
+func TestDutyCommand_Call_OutsideWorkingHours(t *testing.T) {
+	cmd := &DutyCommand{
+		dutyService:  &mockDutyService{result: &duty.DutyResult{DutyID: "johndoe"}},
+		isWorkingNow: func() bool { return false },
+	}
+
+	if _, err := cmd.Call(DutyCall{ActorUserId: "api"}); !errors.Is(err, ErrOutsideWorkingHours) {
+		t.Fatalf("expected ErrOutsideWorkingHours, got %v", err)
+	}
+}
+
+type mockCallTracker struct {
+	dutyID string
+}
+
+func (m *mockCallTracker) Start(dutyID, details string) int64 {
+	m.dutyID = dutyID
+	return 5
+}
+
+func TestDutyCommand_Execute_StartsAcknowledgement(t *testing.T) {
+	messagesChan := make(chan bots.Message, 10)
+	tracker := &mockCallTracker{}
+	cmd := &DutyCommand{
+		dutyService:  &mockDutyService{result: &duty.DutyResult{DutyID: "johndoe", IsNewAssignment: true}},
+		messagesChan: messagesChan,
+		tracker:      tracker,
+	}
+
+	if _, err := cmd.Execute(bots.Command{Name: "duty", ChatId: "main", UserId: "user-1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tracker.dutyID != "johndoe" {
+		t.Fatalf("expected acknowledgement to be requested from johndoe, got %q", tracker.dutyID)
+	}
+
+	msg := <-messagesChan
+	if msg.ChatId != "johndoe" || len(msg.Buttons) != 1 || msg.Buttons[0].Command != "\\ack 5" {
+		t.Fatalf("expected duty message with acknowledge button, got %+v", msg)
+	}
+}
+
+func TestDutyCommand_Execute_MentionsSecondary(t *testing.T) {
+	messagesChan := make(chan bots.Message, 10)
+	cmd := &DutyCommand{
+		dutyService:   &mockDutyService{result: &duty.DutyResult{DutyID: "johndoe", SecondaryDutyID: "janedoe", IsNewAssignment: true}},
+		messagesChan:  messagesChan,
+		supportChatId: "support-123",
+	}
+
+	if _, err := cmd.Execute(bots.Command{Name: "duty", ChatId: "main", Params: map[string]string{}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	close(messagesChan)
+	var recipients []string
+	for msg := range messagesChan {
+		recipients = append(recipients, msg.ChatId)
+		if msg.ChatId == "support-123" && !strings.Contains(msg.Text, "Backup: @[janedoe]") {
+			t.Errorf("expected support message to mention the backup, got %q", msg.Text)
+		}
+	}
+	if strings.Join(recipients, ",") != "johndoe,support-123" {
+		t.Fatalf("expected only the primary and the support chat to be notified, got %v", recipients)
+	}
+}
+
+func TestDutyCommand_Execute_PagesSecondary(t *testing.T) {
+	messagesChan := make(chan bots.Message, 10)
+	cmd := &DutyCommand{
+		dutyService:   &mockDutyService{result: &duty.DutyResult{DutyID: "johndoe", SecondaryDutyID: "janedoe"}},
+		messagesChan:  messagesChan,
+		supportChatId: "support-123",
+	}
+
+	response, err := cmd.Execute(bots.Command{Name: "duty", ChatId: "main", Params: map[string]string{"0": "secondary"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response != "The development team is rushing to help!" {
+		t.Fatalf("unexpected response: %q", response)
+	}
+
+	close(messagesChan)
+	var recipients []string
+	for msg := range messagesChan {
+		recipients = append(recipients, msg.ChatId)
+		if msg.ChatId == "support-123" && !strings.Contains(msg.Text, "Backup on duty today: @[janedoe]") {
+			t.Errorf("unexpected support message %q", msg.Text)
+		}
+	}
+	if strings.Join(recipients, ",") != "janedoe,support-123" {
+		t.Fatalf("expected the backup and the support chat to be notified, got %v", recipients)
+	}
+}
+
+func TestDutyCommand_Execute_NoSecondary(t *testing.T) {
+	cmd := &DutyCommand{
+		dutyService: &mockDutyService{result: &duty.DutyResult{DutyID: "johndoe"}},
+	}
+
+	response, err := cmd.Execute(bots.Command{Name: "duty", ChatId: "main", Params: map[string]string{"0": "secondary"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response != "No backup duty person available for today" {
+		t.Fatalf("unexpected response: %q", response)
+	}
+}
+
+func TestDutyCommand_Execute_RecordsPages(t *testing.T) {
+	service := &mockDutyService{result: &duty.DutyResult{DutyID: "johndoe", SecondaryDutyID: "janedoe"}}
+	cmd := &DutyCommand{dutyService: service}
+
+	for _, params := range []map[string]string{{}, {"0": "secondary"}} {
+		if _, err := cmd.Execute(bots.Command{Name: "duty", ChatId: "main", Params: params}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if strings.Join(service.pages, ",") != "johndoe/primary,janedoe/secondary" {
+		t.Fatalf("expected both pages to be recorded, got %v", service.pages)
+	}
+}
@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"testing"
+	"watch_bot/bots"
+	"watch_bot/dao"
+)
+
+func TestDutyAndNextFlow_InMemory(t *testing.T) {
+	repo := dao.NewMemoryRepository()
+	repo.AddDuty("alice", nil)
+	repo.AddDuty("bob", nil)
+	messagesChan := make(chan bots.Message, 10)
+
+	dutyCmd := NewDutyCommand(DutyCommandConfig{
+		Repository:    repo,
+		MessagesChan:  messagesChan,
+		SupportChatId: "support-123",
+	})
+	nextCmd := NewNextCommand(NextCommandConfig{
+		Repository:         repo,
+		MessagesChan:       messagesChan,
+		SupportChatId:      "support-123",
+		AllowedNextUserIds: []string{"admin"},
+	})
+
+	if _, err := dutyCmd.Execute(bots.Command{Name: "duty", ChatId: "main-1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg := <-messagesChan; msg.ChatId != "alice" {
+		t.Fatalf("expected alice to be notified first, got %q", msg.ChatId)
+	}
+	<-messagesChan
+
+	if _, err := nextCmd.Execute(bots.Command{Name: "next", ChatId: "support-123", UserId: "admin"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg := <-messagesChan; msg.ChatId != "bob" {
+		t.Fatalf("expected bob to be notified after next, got %q", msg.ChatId)
+	}
+	<-messagesChan
+
+	if _, err := dutyCmd.Execute(bots.Command{Name: "duty", ChatId: "main-1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg := <-messagesChan; msg.ChatId != "bob" {
+		t.Fatalf("expected bob to stay on duty, got %q", msg.ChatId)
+	}
+	if len(messagesChan) != 0 {
+		t.Fatalf("expected no support notification for an existing assignment, got %d messages", len(messagesChan))
+	}
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"watch_bot/bots"
+	"watch_bot/dao"
+	"watch_bot/duty"
+)
+
+const (
+	defaultHistoryDays = 7
+	maxHistoryDays     = 90
+)
+
+// HistoryCommandConfig contains configuration for the history command
+type HistoryCommandConfig struct {
+	Repository dao.DutyRepository
+	Location   *time.Location
+}
+
+type historyServicer interface {
+	GetHistory(days int) ([]dao.HistoryEntry, error)
+}
+
+// HistoryCommand handles the \history [N] command
+type HistoryCommand struct {
+	dutyService historyServicer
+}
+
+// NewHistoryCommand creates a new HistoryCommand
+func NewHistoryCommand(config HistoryCommandConfig) *HistoryCommand {
+	return &HistoryCommand{
+		dutyService: duty.NewService(config.Repository).WithLocation(config.Location),
+	}
+}
+
+// Execute lists the duty assignments of the last N days
+func (h *HistoryCommand) Execute(cmd bots.Command) (string, error) {
+	days := defaultHistoryDays
+	if value, ok := cmd.Params["0"]; ok {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxHistoryDays {
+			return fmt.Sprintf("Usage: \\history [days], where days is a number from 1 to %d", maxHistoryDays), nil
+		}
+		days = parsed
+	}
+
+	entries, err := h.dutyService.GetHistory(days)
+	if err != nil {
+		return "", fmt.Errorf("failed to get duty history: %w", err)
+	}
+	if len(entries) == 0 {
+		return fmt.Sprintf("No duty assignments in the last %d days", days), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Duty history for the last %d days:\n", days))
+	for _, entry := range entries {
+		sb.WriteString(formatHistoryEntry(entry))
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
+
+// Description returns command description
+func (h *HistoryCommand) Description() string {
+	return "show duty history for the last N days"
+}
+
+func formatHistoryEntry(entry dao.HistoryEntry) string {
+	details := []string{entry.Reason}
+	if entry.Role == dao.RoleSecondary {
+		details = []string{"backup", entry.Reason}
+	}
+	if entry.ActorUserID != "" {
+		details = append(details, "by "+entry.ActorUserID)
+	}
+	if entry.ReplacedDutyID != "" {
+		details = append(details, "replaced "+entry.ReplacedDutyID)
+	}
+	return fmt.Sprintf("%s %s (%s)", entry.DutyDate.Format("2006-01-02"), entry.DutyID, strings.Join(details, ", "))
+}
@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"watch_bot/bots"
+	"watch_bot/dao"
+)
+
+type mockHistoryService struct {
+	entries []dao.HistoryEntry
+	err     error
+	days    int
+}
+
+func (m *mockHistoryService) GetHistory(days int) ([]dao.HistoryEntry, error) {
+	m.days = days
+	return m.entries, m.err
+}
+
+func TestHistoryCommand_Execute_DefaultDays(t *testing.T) {
+	service := &mockHistoryService{entries: []dao.HistoryEntry{
+		{DutyID: "bob", DutyDate: time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC), ActorUserID: "admin", Reason: dao.ReasonNext, ReplacedDutyID: "alice"},
+		{DutyID: "alice", DutyDate: time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC), ActorUserID: "caller", Reason: dao.ReasonRotation},
+	}}
+	cmd := &HistoryCommand{dutyService: service}
+
+	response, err := cmd.Execute(bots.Command{Name: "history", Params: map[string]string{}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if service.days != defaultHistoryDays {
+		t.Errorf("expected default of %d days, got %d", defaultHistoryDays, service.days)
+	}
+	if !strings.Contains(response, "2026-01-08 bob (next, by admin, replaced alice)") {
+		t.Errorf("expected reassignment line in response, got %q", response)
+	}
+	if !strings.Contains(response, "2026-01-08 alice (rotation, by caller)") {
+		t.Errorf("expected rotation line in response, got %q", response)
+	}
+}
+
+func TestHistoryCommand_Execute_CustomDays(t *testing.T) {
+	service := &mockHistoryService{}
+	cmd := &HistoryCommand{dutyService: service}
+
+	response, err := cmd.Execute(bots.Command{Name: "history", Params: map[string]string{"0": "30"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if service.days != 30 {
+		t.Errorf("expected 30 days, got %d", service.days)
+	}
+	if response != "No duty assignments in the last 30 days" {
+		t.Errorf("unexpected response: %q", response)
+	}
+}
+
+func TestHistoryCommand_Execute_InvalidDays(t *testing.T) {
+	for _, value := range []string{"abc", "0", "1000"} {
+		cmd := &HistoryCommand{dutyService: &mockHistoryService{}}
+		response, err := cmd.Execute(bots.Command{Name: "history", Params: map[string]string{"0": value}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.HasPrefix(response, "Usage:") {
+			t.Errorf("expected usage response for %q, got %q", value, response)
+		}
+	}
+}
+
+func TestHistoryCommand_Execute_ServiceError(t *testing.T) {
+	cmd := &HistoryCommand{dutyService: &mockHistoryService{err: errors.New("db down")}}
+
+	if _, err := cmd.Execute(bots.Command{Name: "history", Params: map[string]string{}}); err == nil {
+		t.Fatal("expected error from service")
+	}
+}
@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"watch_bot/bots"
+	"watch_bot/dao"
+	"watch_bot/working_calendar"
+)
+
+// HolidayCommandConfig contains configuration for the holiday command
+type HolidayCommandConfig struct {
+	UnusualDays *working_calendar.UnusualDays
+	// AllowedUserIds may change the calendar, the same users who may run \next
+	AllowedUserIds []string
+	// IsWorkingDay tells whether a changed date is now a working day, nil leaves it out of the reply
+	IsWorkingDay func(time.Time) bool
+	// Location is the time zone of today's date in \holiday list, nil uses the local time zone
+	Location *time.Location
+}
+
+type unusualDaysStore interface {
+	From(date time.Time) []dao.UnusualDay
+	Add(day dao.UnusualDay) (bool, error)
+	Remove(day time.Time) (bool, error)
+}
+
+// HolidayCommand handles the \holiday add|remove|list <date> [type] [hours] command
+type HolidayCommand struct {
+	unusualDays    unusualDaysStore
+	allowedUserIds map[string]struct{}
+	isWorkingDay   func(time.Time) bool
+	location       *time.Location
+}
+
+// NewHolidayCommand creates a new HolidayCommand
+func NewHolidayCommand(config HolidayCommandConfig) *HolidayCommand {
+	return &HolidayCommand{
+		unusualDays:    config.UnusualDays,
+		allowedUserIds: newAllowedUserIds(config.AllowedUserIds),
+		isWorkingDay:   config.IsWorkingDay,
+		location:       config.Location,
+	}
+}
+
+const holidayUsage = "Usage: \\holiday add <date> [inverted|holiday|working|shortened] [HH:MM-HH:MM], " +
+	"\\holiday remove <date> or \\holiday list [from], dates in YYYY-MM-DD format"
+
+// Execute adds, removes or lists the unusual days of the working calendar
+func (h *HolidayCommand) Execute(cmd bots.Command) (string, error) {
+	if !isAllowedUser(h.allowedUserIds, cmd.UserId) {
+		return "You are not allowed to execute this command", nil
+	}
+
+	action := strings.ToLower(cmd.Params["0"])
+	value, hasDate := cmd.Params["1"]
+	date, err := time.Parse(dateLayout, value)
+	if hasDate && err != nil {
+		return holidayUsage, nil
+	}
+
+	switch {
+	case action == "list":
+		if !hasDate {
+			date = time.Now()
+			if h.location != nil {
+				date = date.In(h.location)
+			}
+		}
+		return h.list(date), nil
+	case action == "add" && hasDate:
+		day, err := parseUnusualDay(date, cmd.Params["2"], cmd.Params["3"])
+		if err != nil {
+			return fmt.Sprintf("%v\n%s", err, holidayUsage), nil
+		}
+		added, err := h.unusualDays.Add(day)
+		if err != nil {
+			return "", fmt.Errorf("failed to add unusual day: %w", err)
+		}
+		if !added {
+			return fmt.Sprintf("%s was updated to %s%s", date.Format(dateLayout), kindOf(day), h.describe(date)), nil
+		}
+		return fmt.Sprintf("%s is now %s%s", date.Format(dateLayout), kindOf(day), h.describe(date)), nil
+	case action == "remove" && hasDate:
+		removed, err := h.unusualDays.Remove(date)
+		if err != nil {
+			return "", fmt.Errorf("failed to remove unusual day: %w", err)
+		}
+		if !removed {
+			return fmt.Sprintf("%s is not an unusual day", date.Format(dateLayout)), nil
+		}
+		return fmt.Sprintf("%s is a usual day again%s", date.Format(dateLayout), h.describe(date)), nil
+	}
+	return holidayUsage, nil
+}
+
+// Description returns command description
+func (h *HolidayCommand) Description() string {
+	return "manage unusual days of the working calendar: \\holiday add|remove|list <date> [type] [hours]"
+}
+
+func (h *HolidayCommand) list(from time.Time) string {
+	days := h.unusualDays.From(from)
+	if len(days) == 0 {
+		return fmt.Sprintf("No unusual days from %s", from.Format(dateLayout))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Unusual days from %s:\n", from.Format(dateLayout)))
+	for _, day := range days {
+		kind := ""
+		if day.TypeOrDefault() != dao.UnusualDayInverted {
+			kind = " " + kindOf(day)
+		}
+		sb.WriteString(fmt.Sprintf("%s %s%s%s\n", day.Date.Format(dateLayout), day.Date.Weekday(), kind, h.describe(day.Date)))
+	}
+	return sb.String()
+}
+
+// describe tells whether the date is a working day, when the command knows the working calendar
+func (h *HolidayCommand) describe(date time.Time) string {
+	if h.isWorkingDay == nil {
+		return ""
+	}
+	if h.isWorkingDay(date) {
+		return " (working day)"
+	}
+	return " (day off)"
+}
+
+// parseUnusualDay builds an unusual day from the optional type and "HH:MM-HH:MM" hours, either bound may be left out
+func parseUnusualDay(date time.Time, dayType, hours string) (dao.UnusualDay, error) {
+	day := dao.UnusualDay{Date: date, Type: strings.ToLower(dayType)}
+	if hours != "" {
+		var found bool
+		day.StartTime, day.EndTime, found = strings.Cut(hours, "-")
+		if !found {
+			return dao.UnusualDay{}, fmt.Errorf("invalid hours %q, expected HH:MM-HH:MM", hours)
+		}
+	}
+	if err := working_calendar.ValidateUnusualDay(day); err != nil {
+		return dao.UnusualDay{}, err
+	}
+	return day, nil
+}
+
+// kindOf names the type of the unusual day together with its hours
+func kindOf(day dao.UnusualDay) string {
+	var kind string
+	switch day.TypeOrDefault() {
+	case dao.UnusualDayHoliday:
+		kind = "a holiday"
+	case dao.UnusualDayWorking:
+		kind = "a working day"
+	case dao.UnusualDayShortened:
+		kind = "a shortened day"
+	default:
+		kind = "an unusual day"
+	}
+	switch {
+	case day.StartTime != "" && day.EndTime != "":
+		kind += fmt.Sprintf(" %s-%s", day.StartTime, day.EndTime)
+	case day.StartTime != "":
+		kind += " from " + day.StartTime
+	case day.EndTime != "":
+		kind += " until " + day.EndTime
+	}
+	return kind
+}
@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"watch_bot/bots"
+	"watch_bot/dao"
+	"watch_bot/working_calendar"
+)
+
+func newTestHolidayCommand(repo *dao.MemoryRepository) *HolidayCommand {
+	return NewHolidayCommand(HolidayCommandConfig{
+		UnusualDays:    working_calendar.NewUnusualDays(repo),
+		AllowedUserIds: []string{"admin"},
+		IsWorkingDay: func(date time.Time) bool {
+			return date.Weekday() != time.Saturday && date.Weekday() != time.Sunday
+		},
+	})
+}
+
+func TestHolidayCommand_AddListRemove(t *testing.T) {
+	repo := dao.NewMemoryRepository()
+	cmd := newTestHolidayCommand(repo)
+	execute := func(params ...string) string {
+		t.Helper()
+		command := bots.Command{Name: "holiday", UserId: "admin", Params: map[string]string{}}
+		for i, param := range params {
+			command.Params[strconv.Itoa(i)] = param
+		}
+		response, err := cmd.Execute(command)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return response
+	}
+
+	if response := execute("add", "2030-05-01"); response != "2030-05-01 is now an unusual day (working day)" {
+		t.Errorf("unexpected add response: %q", response)
+	}
+	if response := execute("add", "2030-05-01"); response != "2030-05-01 was updated to an unusual day (working day)" {
+		t.Errorf("unexpected repeated add response: %q", response)
+	}
+	if response := execute("list", "2030-01-01"); !strings.Contains(response, "2030-05-01 Wednesday (working day)") {
+		t.Errorf("expected the day in the list, got %q", response)
+	}
+	if days, _ := repo.GetUnusualDays(time.Time{}); len(days) != 1 {
+		t.Errorf("expected the day to be stored, got %v", days)
+	}
+	if response := execute("remove", "2030-05-01"); response != "2030-05-01 is a usual day again (working day)" {
+		t.Errorf("unexpected remove response: %q", response)
+	}
+	if response := execute("remove", "2030-05-01"); response != "2030-05-01 is not an unusual day" {
+		t.Errorf("unexpected repeated remove response: %q", response)
+	}
+	if response := execute("list", "2030-01-01"); response != "No unusual days from 2030-01-01" {
+		t.Errorf("unexpected empty list response: %q", response)
+	}
+}
+
+func TestHolidayCommand_AddTyped(t *testing.T) {
+	repo := dao.NewMemoryRepository()
+	cmd := newTestHolidayCommand(repo)
+	execute := func(params map[string]string) string {
+		t.Helper()
+		response, err := cmd.Execute(bots.Command{Name: "holiday", UserId: "admin", Params: params})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return response
+	}
+
+	response := execute(map[string]string{"0": "add", "1": "2030-05-04", "2": "working", "3": "10:00-14:00"})
+	if response != "2030-05-04 is now a working day 10:00-14:00 (day off)" {
+		t.Errorf("unexpected add response: %q", response)
+	}
+	response = execute(map[string]string{"0": "add", "1": "2030-05-08", "2": "Shortened", "3": "-15:00"})
+	if response != "2030-05-08 is now a shortened day until 15:00 (working day)" {
+		t.Errorf("unexpected add response: %q", response)
+	}
+	response = execute(map[string]string{"0": "list", "1": "2030-05-01"})
+	if !strings.Contains(response, "2030-05-04 Saturday a working day 10:00-14:00") ||
+		!strings.Contains(response, "2030-05-08 Wednesday a shortened day until 15:00") {
+		t.Errorf("expected the typed days in the list, got %q", response)
+	}
+	days, _ := repo.GetUnusualDays(time.Time{})
+	if len(days) != 2 || days[0].Type != dao.UnusualDayWorking || days[0].StartTime != "10:00" || days[1].EndTime != "15:00" {
+		t.Errorf("unexpected stored days %+v", days)
+	}
+
+	for _, params := range []map[string]string{
+		{"0": "add", "1": "2030-05-09", "2": "vacation"},
+		{"0": "add", "1": "2030-05-09", "2": "holiday", "3": "10:00-14:00"},
+		{"0": "add", "1": "2030-05-09", "2": "working", "3": "10:00"},
+	} {
+		if response := execute(params); !strings.HasSuffix(response, holidayUsage) {
+			t.Errorf("expected usage for %v, got %q", params, response)
+		}
+	}
+}
+
+func TestHolidayCommand_UsageAndPermission(t *testing.T) {
+	cmd := newTestHolidayCommand(dao.NewMemoryRepository())
+
+	for _, params := range []map[string]string{{}, {"0": "add"}, {"0": "add", "1": "tomorrow"}, {"0": "move", "1": "2030-05-01"}} {
+		response, err := cmd.Execute(bots.Command{UserId: "admin", Params: params})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if response != holidayUsage {
+			t.Errorf("expected usage for %v, got %q", params, response)
+		}
+	}
+
+	response, _ := cmd.Execute(bots.Command{UserId: "user-1", Params: map[string]string{"0": "add", "1": "2030-05-01"}})
+	if response != "You are not allowed to execute this command" {
+		t.Errorf("expected permission denial, got %q", response)
+	}
+}
+
+type failingUnusualDays struct{}
+
+func (failingUnusualDays) From(time.Time) []dao.UnusualDay  { return nil }
+func (failingUnusualDays) Add(dao.UnusualDay) (bool, error) { return false, errors.New("db down") }
+func (failingUnusualDays) Remove(time.Time) (bool, error)   { return false, errors.New("db down") }
+
+func TestHolidayCommand_Error(t *testing.T) {
+	cmd := &HolidayCommand{unusualDays: failingUnusualDays{}, allowedUserIds: newAllowedUserIds([]string{"admin"})}
+
+	if _, err := cmd.Execute(bots.Command{UserId: "admin", Params: map[string]string{"0": "add", "1": "2030-05-01"}}); err == nil {
+		t.Error("expected error")
+	}
+}
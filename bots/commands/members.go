@@ -0,0 +1,156 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"watch_bot/bots"
+	"watch_bot/dao"
+	"watch_bot/duty"
+)
+
+// MemberCommandConfig contains configuration for the members, add and remove commands
+type MemberCommandConfig struct {
+	Repository dao.DutyRepository
+	// AllowedUserIds may manage the rotation, the same users who may run \next
+	AllowedUserIds []string
+}
+
+type memberServicer interface {
+	GetMembers() ([]dao.Duty, error)
+	AddMember(dutyID string) (bool, error)
+	RemoveMember(dutyID string) error
+}
+
+// MembersCommand handles the \members command
+type MembersCommand struct {
+	dutyService    memberServicer
+	allowedUserIds map[string]struct{}
+}
+
+// NewMembersCommand creates a new MembersCommand
+func NewMembersCommand(config MemberCommandConfig) *MembersCommand {
+	return &MembersCommand{
+		dutyService:    duty.NewService(config.Repository),
+		allowedUserIds: newAllowedUserIds(config.AllowedUserIds),
+	}
+}
+
+// Execute lists the active and inactive members of the rotation
+func (m *MembersCommand) Execute(cmd bots.Command) (string, error) {
+	if !isAllowedUser(m.allowedUserIds, cmd.UserId) {
+		return "You are not allowed to execute this command", nil
+	}
+
+	members, err := m.dutyService.GetMembers()
+	if err != nil {
+		return "", fmt.Errorf("failed to get members: %w", err)
+	}
+	if len(members) == 0 {
+		return "Nobody is in the duty rotation", nil
+	}
+
+	var active, inactive []string
+	for _, member := range members {
+		if !member.Active {
+			inactive = append(inactive, member.DutyID)
+			continue
+		}
+		line := member.DutyID
+		if member.Weight != 1 {
+			line += fmt.Sprintf(" (weight %g)", member.Weight)
+		}
+		active = append(active, line)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("In rotation (%d):\n", len(active)))
+	for _, line := range active {
+		sb.WriteString(line + "\n")
+	}
+	if len(inactive) > 0 {
+		sb.WriteString(fmt.Sprintf("\nInactive (%d):\n", len(inactive)))
+		for _, line := range inactive {
+			sb.WriteString(line + "\n")
+		}
+	}
+	return sb.String(), nil
+}
+
+// Description returns command description
+func (m *MembersCommand) Description() string {
+	return "list the members of the duty rotation"
+}
+
+// MemberChangeCommand handles the \add @user and \remove @user commands
+type MemberChangeCommand struct {
+	dutyService    memberServicer
+	allowedUserIds map[string]struct{}
+	add            bool
+}
+
+// NewAddMemberCommand creates a command that adds or reactivates members
+func NewAddMemberCommand(config MemberCommandConfig) *MemberChangeCommand {
+	return &MemberChangeCommand{
+		dutyService:    duty.NewService(config.Repository),
+		allowedUserIds: newAllowedUserIds(config.AllowedUserIds),
+		add:            true,
+	}
+}
+
+// NewRemoveMemberCommand creates a command that deactivates members
+func NewRemoveMemberCommand(config MemberCommandConfig) *MemberChangeCommand {
+	return &MemberChangeCommand{
+		dutyService:    duty.NewService(config.Repository),
+		allowedUserIds: newAllowedUserIds(config.AllowedUserIds),
+		add:            false,
+	}
+}
+
+// Execute adds the mentioned user to the rotation or takes them out of it
+func (m *MemberChangeCommand) Execute(cmd bots.Command) (string, error) {
+	if !isAllowedUser(m.allowedUserIds, cmd.UserId) {
+		return "You are not allowed to execute this command", nil
+	}
+	dutyID := parseMention(cmd.Params["0"])
+	if dutyID == "" {
+		return fmt.Sprintf("Usage: \\%s @user", m.name()), nil
+	}
+
+	if m.add {
+		reactivated, err := m.dutyService.AddMember(dutyID)
+		switch {
+		case errors.Is(err, duty.ErrAlreadyMember):
+			return fmt.Sprintf("%s is already in the duty rotation", dutyID), nil
+		case err != nil:
+			return "", fmt.Errorf("failed to add member: %w", err)
+		case reactivated:
+			return fmt.Sprintf("%s is back in the duty rotation", dutyID), nil
+		}
+		return fmt.Sprintf("%s has joined the duty rotation", dutyID), nil
+	}
+
+	err := m.dutyService.RemoveMember(dutyID)
+	if errors.Is(err, duty.ErrNotInRotation) {
+		return fmt.Sprintf("%s is not in the duty rotation", dutyID), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to remove member: %w", err)
+	}
+	return fmt.Sprintf("%s has left the duty rotation, their history is kept", dutyID), nil
+}
+
+// Description returns command description
+func (m *MemberChangeCommand) Description() string {
+	if m.add {
+		return "add or reactivate a member of the duty rotation: \\add @user"
+	}
+	return "deactivate a member of the duty rotation: \\remove @user"
+}
+
+func (m *MemberChangeCommand) name() string {
+	if m.add {
+		return "add"
+	}
+	return "remove"
+}
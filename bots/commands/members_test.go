@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"watch_bot/bots"
+	"watch_bot/dao"
+	"watch_bot/duty"
+)
+
+type mockMemberService struct {
+	members     []dao.Duty
+	reactivated bool
+	err         error
+	changed     string
+}
+
+func (m *mockMemberService) GetMembers() ([]dao.Duty, error) {
+	return m.members, m.err
+}
+
+func (m *mockMemberService) AddMember(dutyID string) (bool, error) {
+	m.changed = dutyID
+	return m.reactivated, m.err
+}
+
+func (m *mockMemberService) RemoveMember(dutyID string) error {
+	m.changed = dutyID
+	return m.err
+}
+
+func TestMembersCommand_Execute_ListsMembers(t *testing.T) {
+	cmd := &MembersCommand{
+		dutyService: &mockMemberService{members: []dao.Duty{
+			{DutyID: "alice", Weight: 1, Active: true},
+			{DutyID: "bob", Weight: 0.5, Active: true},
+			{DutyID: "carol", Weight: 1},
+		}},
+		allowedUserIds: newAllowedUserIds([]string{"admin"}),
+	}
+
+	response, err := cmd.Execute(bots.Command{Name: "members", UserId: "admin"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "In rotation (2):\nalice\nbob (weight 0.5)\n\nInactive (1):\ncarol\n"
+	if response != expected {
+		t.Errorf("expected %q, got %q", expected, response)
+	}
+}
+
+func TestMemberCommands_RequirePermission(t *testing.T) {
+	service := &mockMemberService{}
+	config := MemberCommandConfig{Repository: dao.NewMemoryRepository(), AllowedUserIds: []string{"admin"}}
+	commands := []bots.CommandHandler{NewMembersCommand(config), NewAddMemberCommand(config), NewRemoveMemberCommand(config)}
+	for _, command := range commands {
+		switch c := command.(type) {
+		case *MembersCommand:
+			c.dutyService = service
+		case *MemberChangeCommand:
+			c.dutyService = service
+		}
+		response, err := command.Execute(bots.Command{UserId: "user-1", Params: map[string]string{"0": "@bob"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if response != "You are not allowed to execute this command" {
+			t.Errorf("expected permission denial, got %q", response)
+		}
+	}
+	if service.changed != "" {
+		t.Errorf("expected no change, got %q", service.changed)
+	}
+}
+
+func TestMemberChangeCommand_Execute(t *testing.T) {
+	tests := []struct {
+		name        string
+		add         bool
+		param       string
+		reactivated bool
+		err         error
+		want        string
+	}{
+		{"add", true, "@[bob]", false, nil, "bob has joined the duty rotation"},
+		{"reactivate", true, "@bob", true, nil, "bob is back in the duty rotation"},
+		{"add twice", true, "bob", false, duty.ErrAlreadyMember, "bob is already in the duty rotation"},
+		{"remove", false, "@bob", false, nil, "bob has left the duty rotation, their history is kept"},
+		{"remove stranger", false, "@bob", false, duty.ErrNotInRotation, "bob is not in the duty rotation"},
+		{"usage", false, "", false, nil, "Usage: \\remove @user"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &mockMemberService{reactivated: tt.reactivated, err: tt.err}
+			cmd := &MemberChangeCommand{dutyService: service, allowedUserIds: newAllowedUserIds([]string{"admin"}), add: tt.add}
+
+			response, err := cmd.Execute(bots.Command{UserId: "admin", Params: map[string]string{"0": tt.param}})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if response != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, response)
+			}
+		})
+	}
+}
+
+func TestMemberChangeCommand_Execute_Error(t *testing.T) {
+	cmd := &MemberChangeCommand{
+		dutyService:    &mockMemberService{err: errors.New("db down")},
+		allowedUserIds: newAllowedUserIds([]string{"admin"}),
+		add:            true,
+	}
+
+	_, err := cmd.Execute(bots.Command{UserId: "admin", Params: map[string]string{"0": "@bob"}})
+	if err == nil || !strings.Contains(err.Error(), "db down") {
+		t.Errorf("expected the repository error, got %v", err)
+	}
+}
@@ -4,22 +4,26 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 	"watch_bot/bots"
+	"watch_bot/dao"
 	"watch_bot/duty"
 
 	botgolang "github.com/mail-ru-im/bot-golang"
 )
 
 type NextCommandConfig struct {
-	ConnectionStr      string
+	Repository         dao.DutyRepository
 	MessagesChan       chan bots.Message
 	SupportChatId      string
 	AllowedNextUserIds []string
 	IsWorkingNow       func() bool
+	Rotation           duty.Rotation
+	Location           *time.Location
 }
 
 type nextDutyServicer interface {
-	GetNextDuty() (*duty.DutyResult, error)
+	GetNextDuty(actorUserId string) (*duty.DutyResult, error)
 }
 
 type NextCommand struct {
@@ -32,7 +36,7 @@ type NextCommand struct {
 
 func NewNextCommand(config NextCommandConfig) *NextCommand {
 	return &NextCommand{
-		dutyService:        duty.NewService(config.ConnectionStr),
+		dutyService:        duty.NewService(config.Repository).WithRotation(config.Rotation).WithLocation(config.Location),
 		messagesChan:       config.MessagesChan,
 		supportChatId:      config.SupportChatId,
 		allowedNextUserIds: newAllowedUserIds(config.AllowedNextUserIds),
@@ -49,7 +53,7 @@ func (n *NextCommand) Execute(cmd bots.Command) (string, error) {
 		return "Duty can only be changed during working hours", nil
 	}
 
-	result, err := n.dutyService.GetNextDuty()
+	result, err := n.dutyService.GetNextDuty(cmd.UserId)
 	if err != nil {
 		return "", fmt.Errorf("failed to get next duty: %w", err)
 	}
@@ -89,10 +93,15 @@ func (n *NextCommand) Description() string {
 }
 
 func (n *NextCommand) isUserAllowed(userId string) bool {
-	if len(n.allowedNextUserIds) == 0 {
+	return isAllowedUser(n.allowedNextUserIds, userId)
+}
+
+// isAllowedUser reports whether userId is in the allowed set, nobody is allowed when the set is empty
+func isAllowedUser(allowed map[string]struct{}, userId string) bool {
+	if len(allowed) == 0 {
 		return false
 	}
-	_, ok := n.allowedNextUserIds[userId]
+	_, ok := allowed[userId]
 	return ok
 }
 
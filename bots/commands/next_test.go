@@ -12,7 +12,7 @@ type mockNextDutyService struct {
 	err    error
 }
 
-func (m *mockNextDutyService) GetNextDuty() (*duty.DutyResult, error) {
+func (m *mockNextDutyService) GetNextDuty(actorUserId string) (*duty.DutyResult, error) {
 	return m.result, m.err
 }
 
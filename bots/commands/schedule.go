@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"watch_bot/bots"
+	"watch_bot/dao"
+	"watch_bot/duty"
+)
+
+// ScheduleCommandConfig contains configuration for the schedule command
+type ScheduleCommandConfig struct {
+	Repository   dao.DutyRepository
+	IsWorkingDay func(time.Time) bool
+	Rotation     duty.Rotation
+	Location     *time.Location
+}
+
+type scheduleServicer interface {
+	GetSchedule(days int, isWorkingDay func(time.Time) bool) ([]duty.ScheduledDay, error)
+}
+
+// ScheduleCommand handles the \schedule [days] command
+type ScheduleCommand struct {
+	dutyService  scheduleServicer
+	isWorkingDay func(time.Time) bool
+}
+
+// NewScheduleCommand creates a new ScheduleCommand
+func NewScheduleCommand(config ScheduleCommandConfig) *ScheduleCommand {
+	return &ScheduleCommand{
+		dutyService:  duty.NewService(config.Repository).WithRotation(config.Rotation).WithLocation(config.Location),
+		isWorkingDay: config.IsWorkingDay,
+	}
+}
+
+// Execute shows the projected duty rotation for the next working days
+func (s *ScheduleCommand) Execute(cmd bots.Command) (string, error) {
+	days := duty.DefaultScheduleDays
+	if value, ok := cmd.Params["0"]; ok {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > duty.MaxScheduleDays {
+			return fmt.Sprintf("Usage: \\schedule [days], where days is a number from 1 to %d", duty.MaxScheduleDays), nil
+		}
+		days = parsed
+	}
+
+	schedule, err := s.dutyService.GetSchedule(days, s.isWorkingDay)
+	if err != nil {
+		return "", fmt.Errorf("failed to get duty schedule: %w", err)
+	}
+	if len(schedule) == 0 {
+		return "No working days found for the schedule", nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Duty schedule for the next %d working days:\n", len(schedule)))
+	for _, day := range schedule {
+		dutyID := day.DutyID
+		if dutyID == "" {
+			dutyID = "nobody available"
+		}
+		sb.WriteString(fmt.Sprintf("%s %s %s", day.Date.Format(dateLayout), day.Date.Format("Mon"), dutyID))
+		if day.ReplacedDutyID != "" {
+			sb.WriteString(fmt.Sprintf(" (swap with %s)", day.ReplacedDutyID))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
+
+// Description returns command description
+func (s *ScheduleCommand) Description() string {
+	return "show projected duty schedule for the next N working days"
+}
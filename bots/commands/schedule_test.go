@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"watch_bot/bots"
+	"watch_bot/duty"
+)
+
+type mockScheduleService struct {
+	schedule []duty.ScheduledDay
+	err      error
+	days     int
+}
+
+func (m *mockScheduleService) GetSchedule(days int, isWorkingDay func(time.Time) bool) ([]duty.ScheduledDay, error) {
+	m.days = days
+	return m.schedule, m.err
+}
+
+func TestScheduleCommand_Execute(t *testing.T) {
+	service := &mockScheduleService{schedule: []duty.ScheduledDay{
+		{Date: time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC), DutyID: "alice"},
+		{Date: time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC), DutyID: "charlie", ReplacedDutyID: "bob"},
+	}}
+	cmd := &ScheduleCommand{dutyService: service}
+
+	response, err := cmd.Execute(bots.Command{Name: "schedule", Params: map[string]string{"0": "2"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if service.days != 2 {
+		t.Errorf("expected 2 days to be requested, got %d", service.days)
+	}
+	if !strings.Contains(response, "2026-01-08 Thu alice\n") {
+		t.Errorf("expected alice on Thursday, got %q", response)
+	}
+	if !strings.Contains(response, "2026-01-09 Fri charlie (swap with bob)\n") {
+		t.Errorf("expected swapped Friday, got %q", response)
+	}
+}
+
+func TestScheduleCommand_Execute_InvalidDays(t *testing.T) {
+	cmd := &ScheduleCommand{dutyService: &mockScheduleService{}}
+
+	response, err := cmd.Execute(bots.Command{Name: "schedule", Params: map[string]string{"0": "-1"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(response, "Usage:") {
+		t.Errorf("expected usage response, got %q", response)
+	}
+}
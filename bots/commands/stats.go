@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"watch_bot/bots"
+	"watch_bot/dao"
+	"watch_bot/duty"
+)
+
+// StatsCommandConfig contains configuration for the stats command
+type StatsCommandConfig struct {
+	Repository dao.DutyRepository
+	// IsRegularDay tells ordinary working days from days off and unusual days
+	IsRegularDay func(time.Time) bool
+	// Location ends the statistics period at midnight of the team's time zone, nil uses the local one
+	Location *time.Location
+}
+
+type statsServicer interface {
+	GetStats(days int, isRegularDay func(time.Time) bool) ([]duty.PersonStats, error)
+}
+
+// StatsCommand handles the \stats [N] command
+type StatsCommand struct {
+	dutyService  statsServicer
+	isRegularDay func(time.Time) bool
+}
+
+// NewStatsCommand creates a new StatsCommand
+func NewStatsCommand(config StatsCommandConfig) *StatsCommand {
+	return &StatsCommand{
+		dutyService:  duty.NewService(config.Repository).WithLocation(config.Location),
+		isRegularDay: config.IsRegularDay,
+	}
+}
+
+// Execute shows the duty load of every person over the last N days
+func (s *StatsCommand) Execute(cmd bots.Command) (string, error) {
+	days := duty.DefaultStatsDays
+	if value, ok := cmd.Params["0"]; ok {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > duty.MaxStatsDays {
+			return fmt.Sprintf("Usage: \\stats [days], where days is a number from 1 to %d", duty.MaxStatsDays), nil
+		}
+		days = parsed
+	}
+
+	stats, err := s.dutyService.GetStats(days, s.isRegularDay)
+	if err != nil {
+		return "", fmt.Errorf("failed to get duty statistics: %w", err)
+	}
+	if len(stats) == 0 {
+		return "Nobody is in the duty rotation", nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Duty statistics for the last %d days:\n", days))
+	for _, person := range stats {
+		sb.WriteString(fmt.Sprintf("%s: %d duty days, %d off-day shifts, %d pages, %d skipped by \\next\n",
+			person.DutyID, person.DutyDays, person.OffDayShifts, person.Pages, person.NextSkips))
+	}
+	return sb.String(), nil
+}
+
+// Description returns command description
+func (s *StatsCommand) Description() string {
+	return "show duty days, off-day shifts, pages and \\next skips per person for the last N days"
+}
@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"watch_bot/bots"
+	"watch_bot/duty"
+)
+
+type mockStatsService struct {
+	stats []duty.PersonStats
+	err   error
+	days  int
+}
+
+func (m *mockStatsService) GetStats(days int, isRegularDay func(time.Time) bool) ([]duty.PersonStats, error) {
+	m.days = days
+	return m.stats, m.err
+}
+
+func TestStatsCommand_Execute_DefaultDays(t *testing.T) {
+	service := &mockStatsService{stats: []duty.PersonStats{
+		{DutyID: "alice", DutyDays: 10, NextSkips: 1, Pages: 4, OffDayShifts: 2},
+		{DutyID: "bob", DutyDays: 8},
+	}}
+	cmd := &StatsCommand{dutyService: service}
+
+	response, err := cmd.Execute(bots.Command{Name: "stats", Params: map[string]string{}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if service.days != duty.DefaultStatsDays {
+		t.Errorf("expected default of %d days, got %d", duty.DefaultStatsDays, service.days)
+	}
+	for _, expected := range []string{
+		"alice: 10 duty days, 2 off-day shifts, 4 pages, 1 skipped by \\next",
+		"bob: 8 duty days, 0 off-day shifts, 0 pages, 0 skipped by \\next",
+	} {
+		if !strings.Contains(response, expected) {
+			t.Errorf("expected %q in response, got %q", expected, response)
+		}
+	}
+}
+
+func TestStatsCommand_Execute_InvalidDays(t *testing.T) {
+	service := &mockStatsService{}
+	cmd := &StatsCommand{dutyService: service}
+
+	for _, value := range []string{"0", "abc", "366"} {
+		response, err := cmd.Execute(bots.Command{Name: "stats", Params: map[string]string{"0": value}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.HasPrefix(response, "Usage:") {
+			t.Errorf("expected usage for %q, got %q", value, response)
+		}
+	}
+	if service.days != 0 {
+		t.Errorf("expected statistics not to be requested, got %d days", service.days)
+	}
+}
+
+func TestStatsCommand_Execute_Error(t *testing.T) {
+	cmd := &StatsCommand{dutyService: &mockStatsService{err: errors.New("db down")}}
+
+	if _, err := cmd.Execute(bots.Command{Name: "stats", Params: map[string]string{"0": "7"}}); err == nil {
+		t.Error("expected error")
+	}
+}
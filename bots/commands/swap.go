@@ -0,0 +1,166 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"watch_bot/bots"
+	"watch_bot/dao"
+	"watch_bot/duty"
+)
+
+// SwapCommandConfig contains configuration for the swap, accept and decline commands
+type SwapCommandConfig struct {
+	Repository   dao.DutyRepository
+	MessagesChan chan bots.Message
+	Location     *time.Location
+	// IsWorkingDay tells the days the rotation moves on, nil treats every day as a working day
+	IsWorkingDay func(time.Time) bool
+	Rotation     duty.Rotation
+}
+
+type swapServicer interface {
+	RequestSwap(requesterUserId string, date time.Time, targetUserId string, isWorkingDay func(time.Time) bool) (*dao.Swap, error)
+	ResolveSwap(targetUserId string, swapID int64, accept bool) (*dao.Swap, error)
+}
+
+// SwapCommand handles the \swap <date> @user command
+type SwapCommand struct {
+	dutyService  swapServicer
+	messagesChan chan bots.Message
+	isWorkingDay func(time.Time) bool
+}
+
+// NewSwapCommand creates a new SwapCommand
+func NewSwapCommand(config SwapCommandConfig) *SwapCommand {
+	return &SwapCommand{
+		dutyService:  duty.NewService(config.Repository).WithRotation(config.Rotation).WithLocation(config.Location),
+		messagesChan: config.MessagesChan,
+		isWorkingDay: config.IsWorkingDay,
+	}
+}
+
+// Execute creates a pending swap and asks the other person to confirm it
+func (s *SwapCommand) Execute(cmd bots.Command) (string, error) {
+	date, err := time.Parse(dateLayout, cmd.Params["0"])
+	target := parseMention(cmd.Params["1"])
+	if err != nil || target == "" {
+		return "Usage: \\swap <date> @user, date in YYYY-MM-DD format", nil
+	}
+
+	swap, err := s.dutyService.RequestSwap(cmd.UserId, date, target, s.isWorkingDay)
+	switch {
+	case errors.Is(err, duty.ErrNotInRotation):
+		return "You are not in the duty rotation", nil
+	case errors.Is(err, duty.ErrSwapTargetNotInRotation):
+		return fmt.Sprintf("%s is not in the duty rotation", target), nil
+	case errors.Is(err, duty.ErrSwapWithSelf):
+		return "You cannot swap duty with yourself", nil
+	case errors.Is(err, duty.ErrSwapInPast):
+		return "You cannot swap a date in the past", nil
+	case errors.Is(err, duty.ErrSwapTooFar):
+		return "You cannot swap a date more than a year ahead", nil
+	case errors.Is(err, duty.ErrSwapNotOnDuty):
+		return fmt.Sprintf("You are not on duty on %s", date.Format(dateLayout)), nil
+	case errors.Is(err, duty.ErrSwapTargetAbsent):
+		return fmt.Sprintf("%s is away on %s", target, date.Format(dateLayout)), nil
+	case err != nil:
+		return "", fmt.Errorf("failed to request swap: %w", err)
+	}
+
+	bots.SendNonBlocking(s.messagesChan, bots.Message{
+		ChatId: swap.TargetDutyID,
+		Text: fmt.Sprintf("%s asks you to take their duty on %s. Reply \\accept %d or \\decline %d",
+			swap.RequesterDutyID, swap.Date.Format(dateLayout), swap.ID, swap.ID),
+	})
+
+	return fmt.Sprintf("Swap #%d requested: %s takes the duty of %s on %s after confirming with \\accept",
+		swap.ID, swap.TargetDutyID, swap.RequesterDutyID, swap.Date.Format(dateLayout)), nil
+}
+
+// Description returns command description
+func (s *SwapCommand) Description() string {
+	return "ask someone to take your duty: \\swap <date> @user"
+}
+
+// SwapResponseCommand handles the \accept [id] and \decline [id] commands
+type SwapResponseCommand struct {
+	dutyService  swapServicer
+	messagesChan chan bots.Message
+	accept       bool
+}
+
+// NewAcceptSwapCommand creates a command that accepts pending swaps
+func NewAcceptSwapCommand(config SwapCommandConfig) *SwapResponseCommand {
+	return &SwapResponseCommand{
+		dutyService:  duty.NewService(config.Repository).WithLocation(config.Location),
+		messagesChan: config.MessagesChan,
+		accept:       true,
+	}
+}
+
+// NewDeclineSwapCommand creates a command that declines pending swaps
+func NewDeclineSwapCommand(config SwapCommandConfig) *SwapResponseCommand {
+	return &SwapResponseCommand{
+		dutyService:  duty.NewService(config.Repository).WithLocation(config.Location),
+		messagesChan: config.MessagesChan,
+		accept:       false,
+	}
+}
+
+// Execute resolves the given or the most recent pending swap addressed to the caller
+func (s *SwapResponseCommand) Execute(cmd bots.Command) (string, error) {
+	var swapID int64
+	if value, ok := cmd.Params["0"]; ok {
+		parsed, err := strconv.ParseInt(strings.TrimPrefix(value, "#"), 10, 64)
+		if err != nil || parsed <= 0 {
+			return fmt.Sprintf("Usage: \\%s [swap id]", s.name()), nil
+		}
+		swapID = parsed
+	}
+
+	swap, err := s.dutyService.ResolveSwap(cmd.UserId, swapID, s.accept)
+	switch {
+	case errors.Is(err, duty.ErrNotInRotation):
+		return "You are not in the duty rotation", nil
+	case errors.Is(err, duty.ErrNoPendingSwap):
+		return "You have no pending swap requests", nil
+	case err != nil:
+		return "", fmt.Errorf("failed to %s swap: %w", s.name(), err)
+	}
+
+	bots.SendNonBlocking(s.messagesChan, bots.Message{
+		ChatId: swap.RequesterDutyID,
+		Text:   fmt.Sprintf("%s has %s your swap for %s", swap.TargetDutyID, swap.Status, swap.Date.Format(dateLayout)),
+	})
+
+	if s.accept {
+		return fmt.Sprintf("Swap #%d accepted: %s is on duty on %s instead of %s",
+			swap.ID, swap.TargetDutyID, swap.Date.Format(dateLayout), swap.RequesterDutyID), nil
+	}
+	return fmt.Sprintf("Swap #%d declined", swap.ID), nil
+}
+
+// Description returns command description
+func (s *SwapResponseCommand) Description() string {
+	if s.accept {
+		return "accept a swap request addressed to you"
+	}
+	return "decline a swap request addressed to you"
+}
+
+func (s *SwapResponseCommand) name() string {
+	if s.accept {
+		return "accept"
+	}
+	return "decline"
+}
+
+// parseMention extracts a user ID from "@user", "@[user]" or "user"
+func parseMention(value string) string {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "@")
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+	return value
+}
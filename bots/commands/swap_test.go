@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"watch_bot/bots"
+	"watch_bot/dao"
+	"watch_bot/duty"
+)
+
+type mockSwapService struct {
+	swap      *dao.Swap
+	err       error
+	requester string
+	target    string
+	swapID    int64
+	accept    bool
+}
+
+func (m *mockSwapService) RequestSwap(requesterUserId string, date time.Time, targetUserId string, isWorkingDay func(time.Time) bool) (*dao.Swap, error) {
+	m.requester, m.target = requesterUserId, targetUserId
+	return m.swap, m.err
+}
+
+func (m *mockSwapService) ResolveSwap(targetUserId string, swapID int64, accept bool) (*dao.Swap, error) {
+	m.target, m.swapID, m.accept = targetUserId, swapID, accept
+	return m.swap, m.err
+}
+
+func TestSwapCommand_Execute_NotifiesTarget(t *testing.T) {
+	messagesChan := make(chan bots.Message, 10)
+	service := &mockSwapService{swap: &dao.Swap{
+		ID: 7, Date: time.Date(2026, 2, 3, 0, 0, 0, 0, time.UTC), RequesterDutyID: "alice", TargetDutyID: "bob", Status: dao.SwapPending,
+	}}
+	cmd := &SwapCommand{dutyService: service, messagesChan: messagesChan}
+
+	response, err := cmd.Execute(bots.Command{Name: "swap", UserId: "alice", Params: map[string]string{"0": "2026-02-03", "1": "@[bob]"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if service.requester != "alice" || service.target != "bob" {
+		t.Errorf("unexpected swap request: %+v", service)
+	}
+	if !strings.Contains(response, "Swap #7") {
+		t.Errorf("unexpected response: %q", response)
+	}
+	if len(messagesChan) != 1 {
+		t.Fatalf("expected 1 notification, got %d", len(messagesChan))
+	}
+	if msg := <-messagesChan; msg.ChatId != "bob" || !strings.Contains(msg.Text, "\\accept 7") {
+		t.Errorf("unexpected notification: %+v", msg)
+	}
+}
+
+func TestSwapCommand_Execute_Usage(t *testing.T) {
+	for _, params := range []map[string]string{{}, {"0": "2026-02-03"}, {"0": "tomorrow", "1": "@bob"}} {
+		cmd := &SwapCommand{dutyService: &mockSwapService{}}
+		response, err := cmd.Execute(bots.Command{Name: "swap", UserId: "alice", Params: params})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.HasPrefix(response, "Usage:") {
+			t.Errorf("expected usage response for %v, got %q", params, response)
+		}
+	}
+}
+
+func TestSwapCommand_Execute_TargetNotInRotation(t *testing.T) {
+	cmd := &SwapCommand{dutyService: &mockSwapService{err: duty.ErrSwapTargetNotInRotation}}
+
+	response, err := cmd.Execute(bots.Command{Name: "swap", UserId: "alice", Params: map[string]string{"0": "2026-02-03", "1": "@eve"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response != "eve is not in the duty rotation" {
+		t.Errorf("unexpected response: %q", response)
+	}
+}
+
+func TestSwapCommand_Execute_RejectedSwaps(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{duty.ErrSwapNotOnDuty, "You are not on duty on 2026-02-03"},
+		{duty.ErrSwapTargetAbsent, "eve is away on 2026-02-03"},
+		{duty.ErrSwapTooFar, "You cannot swap a date more than a year ahead"},
+	}
+	for _, tt := range tests {
+		cmd := &SwapCommand{dutyService: &mockSwapService{err: tt.err}}
+
+		response, err := cmd.Execute(bots.Command{Name: "swap", UserId: "alice", Params: map[string]string{"0": "2026-02-03", "1": "@eve"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if response != tt.want {
+			t.Errorf("expected %q, got %q", tt.want, response)
+		}
+	}
+}
+
+func TestSwapResponseCommand_Accept(t *testing.T) {
+	messagesChan := make(chan bots.Message, 10)
+	service := &mockSwapService{swap: &dao.Swap{
+		ID: 7, Date: time.Date(2026, 2, 3, 0, 0, 0, 0, time.UTC), RequesterDutyID: "alice", TargetDutyID: "bob", Status: dao.SwapAccepted,
+	}}
+	cmd := &SwapResponseCommand{dutyService: service, messagesChan: messagesChan, accept: true}
+
+	response, err := cmd.Execute(bots.Command{Name: "accept", UserId: "bob", Params: map[string]string{"0": "7"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !service.accept || service.swapID != 7 || service.target != "bob" {
+		t.Errorf("unexpected resolution: %+v", service)
+	}
+	if !strings.Contains(response, "bob is on duty on 2026-02-03 instead of alice") {
+		t.Errorf("unexpected response: %q", response)
+	}
+	if msg := <-messagesChan; msg.ChatId != "alice" || !strings.Contains(msg.Text, "accepted") {
+		t.Errorf("unexpected notification: %+v", msg)
+	}
+}
+
+func TestSwapResponseCommand_DeclineWithoutPending(t *testing.T) {
+	cmd := &SwapResponseCommand{dutyService: &mockSwapService{err: duty.ErrNoPendingSwap}, accept: false}
+
+	response, err := cmd.Execute(bots.Command{Name: "decline", UserId: "bob", Params: map[string]string{}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response != "You have no pending swap requests" {
+		t.Errorf("unexpected response: %q", response)
+	}
+}
+
+func TestParseMention(t *testing.T) {
+	for input, expected := range map[string]string{"@bob": "bob", "@[bob@corp.ru]": "bob@corp.ru", "bob": "bob", "": ""} {
+		if got := parseMention(input); got != expected {
+			t.Errorf("parseMention(%q) = %q, want %q", input, got, expected)
+		}
+	}
+}
@@ -14,6 +14,7 @@ type TelegramBot struct {
 	Bot           *tgbotapi.BotAPI
 	MainChatId    string
 	SupportChatId string
+	TeamChatIds   []string
 }
 
 func (b *TelegramBot) ListenIncomingMessages(ctx context.Context, messages chan Command) {
@@ -34,24 +35,17 @@ func (b *TelegramBot) ListenIncomingMessages(ctx context.Context, messages chan
 			if !ok {
 				return
 			}
-			if update.Message != nil {
-				chatId := strconv.FormatInt(update.Message.Chat.ID, 10)
-				if !isAllowedCommandChat(chatId, b.MainChatId, b.SupportChatId) {
-					log.Printf("Ignoring message from chat %s (not allowed command chat)", chatId)
-					continue
+			cmd := tgCommandFromUpdate(update, append([]string{b.MainChatId, b.SupportChatId}, b.TeamChatIds...)...)
+			if update.CallbackQuery != nil {
+				if _, err := b.Bot.AnswerCallbackQuery(tgbotapi.NewCallback(update.CallbackQuery.ID, "")); err != nil {
+					log.Printf("failed to answer callback query: %v", err)
 				}
-				log.Printf("Received message: %s from user id %d", update.Message.Text, update.Message.Chat.ID)
-				userId := ""
-				if update.Message.From != nil {
-					userId = strconv.Itoa(update.Message.From.ID)
-				}
-				cmd := ParseCommand(update.Message.Text, chatId, userId)
-				if cmd != nil {
-					select {
-					case <-ctx.Done():
-						return
-					case messages <- *cmd:
-					}
+			}
+			if cmd != nil {
+				select {
+				case <-ctx.Done():
+					return
+				case messages <- *cmd:
 				}
 			}
 		}
@@ -85,6 +79,12 @@ func (b *TelegramBot) ListenMessagesToSend(ctx context.Context, messagesChannel
 				continue
 			}
 			msg := tgbotapi.NewMessage(chatIdInt, message.Text)
+			if message.ParseMode == tgbotapi.ModeHTML {
+				msg.ParseMode = tgbotapi.ModeHTML
+			}
+			if len(message.Buttons) > 0 {
+				msg.ReplyMarkup = tgKeyboard(message.Buttons)
+			}
 			sendFunc := func() error {
 				_, err := b.Bot.Send(msg)
 				return err
@@ -110,3 +110,38 @@ func tgSendWithRetry(ctx context.Context, sendFunc func() error, retryCount, ret
 		}
 	}
 }
+
+// tgCommandFromUpdate turns a message or a pressed inline button into a command.
+// Commands are accepted from the configured chats, private chats may only send \ack.
+func tgCommandFromUpdate(update tgbotapi.Update, allowedChatIds ...string) *Command {
+	message := update.Message
+	text := ""
+	var from *tgbotapi.User
+	if update.CallbackQuery != nil {
+		message = update.CallbackQuery.Message
+		text = update.CallbackQuery.Data
+		from = update.CallbackQuery.From
+	} else if message != nil {
+		text = message.Text
+		from = message.From
+	}
+	if message == nil || message.Chat == nil {
+		return nil
+	}
+
+	chatId := strconv.FormatInt(message.Chat.ID, 10)
+	log.Printf("Received message: %s from chat id %d", text, message.Chat.ID)
+	userId := ""
+	if from != nil {
+		userId = strconv.Itoa(from.ID)
+	}
+	return commandFromChat(text, chatId, userId, message.Chat.IsPrivate(), allowedChatIds...)
+}
+
+func tgKeyboard(buttons []Button) tgbotapi.InlineKeyboardMarkup {
+	row := make([]tgbotapi.InlineKeyboardButton, 0, len(buttons))
+	for _, button := range buttons {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(button.Text, button.Command))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(row)
+}
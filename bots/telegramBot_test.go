@@ -5,6 +5,8 @@ import (
 	"errors"
 	"testing"
 	"time"
+
+	"github.com/go-telegram-bot-api/telegram-bot-api"
 )
 
 func TestTgSendWithRetry(t *testing.T) {
@@ -80,3 +82,70 @@ func TestTgSendWithRetryStopsOnContextCancellation(t *testing.T) {
 		t.Errorf("got %d attempts, want 1", sendCount)
 	}
 }
+
+func TestTgCommandFromUpdate(t *testing.T) {
+	group := &tgbotapi.Chat{ID: -100, Type: "supergroup"}
+	private := &tgbotapi.Chat{ID: 42, Type: "private"}
+	user := &tgbotapi.User{ID: 42}
+
+	tests := []struct {
+		name       string
+		update     tgbotapi.Update
+		wantName   string
+		wantChatId string
+	}{
+		{
+			name:       "command in allowed chat",
+			update:     tgbotapi.Update{Message: &tgbotapi.Message{Chat: group, From: user, Text: "\\duty"}},
+			wantName:   "duty",
+			wantChatId: "-100",
+		},
+		{
+			name:   "command in other group",
+			update: tgbotapi.Update{Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: -200, Type: "group"}, From: user, Text: "\\duty"}},
+		},
+		{
+			name:       "command in private chat",
+			update:     tgbotapi.Update{Message: &tgbotapi.Message{Chat: private, From: user, Text: "\\ack"}},
+			wantName:   "ack",
+			wantChatId: "42",
+		},
+		{
+			name:   "duty in private chat",
+			update: tgbotapi.Update{Message: &tgbotapi.Message{Chat: private, From: user, Text: "\\duty"}},
+		},
+		{
+			name:   "next in private chat",
+			update: tgbotapi.Update{Message: &tgbotapi.Message{Chat: private, From: user, Text: "\\next"}},
+		},
+		{
+			name: "inline button",
+			update: tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+				ID:      "query",
+				From:    user,
+				Message: &tgbotapi.Message{Chat: private},
+				Data:    "\\ack 3",
+			}},
+			wantName:   "ack",
+			wantChatId: "42",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := tgCommandFromUpdate(tt.update, "-100")
+			if tt.wantName == "" {
+				if cmd != nil {
+					t.Fatalf("expected update to be ignored, got %+v", cmd)
+				}
+				return
+			}
+			if cmd == nil {
+				t.Fatal("expected command, got nil")
+			}
+			if cmd.Name != tt.wantName || cmd.ChatId != tt.wantChatId || cmd.UserId != "42" {
+				t.Errorf("unexpected command %+v", cmd)
+			}
+		})
+	}
+}
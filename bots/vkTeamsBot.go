@@ -13,6 +13,7 @@ type VkTeamsBot struct {
 	BotApiUrl     string
 	MainChatId    string
 	SupportChatId string
+	TeamChatIds   []string
 }
 
 func (b VkTeamsBot) ListenIncomingMessages(ctx context.Context, messages chan Command) {
@@ -26,13 +27,18 @@ func (b VkTeamsBot) ListenIncomingMessages(ctx context.Context, messages chan Co
 			if !ok {
 				return
 			}
-			chatId := update.Payload.Chat.ID
-			if !isAllowedCommandChat(chatId, b.MainChatId, b.SupportChatId) {
-				log.Printf("Ignoring message from chat %s (not allowed command chat)", chatId)
-				continue
+			chat := update.Payload.Chat
+			text := update.Payload.Text
+			if update.Type == botgolang.CALLBACK_QUERY {
+				chat = update.Payload.CallbackMsg.Chat
+				text = update.Payload.CallbackData
+				if err := update.Payload.CallbackQuery().Send(); err != nil {
+					log.Printf("failed to answer callback query: %v", err)
+				}
 			}
-			log.Println("Received message:", update.Payload.Text)
-			cmd := ParseCommand(update.Payload.Text, chatId, update.Payload.From.ID)
+			log.Println("Received message:", text)
+			cmd := commandFromChat(text, chat.ID, update.Payload.From.ID, chat.Type == botgolang.Private,
+				append([]string{b.MainChatId, b.SupportChatId}, b.TeamChatIds...)...)
 			if cmd != nil {
 				select {
 				case <-ctx.Done():
@@ -66,6 +72,9 @@ func (b VkTeamsBot) ListenMessagesToSend(ctx context.Context, messagesChannel ch
 				return
 			}
 			botMessage := b.Bot.NewTextMessage(message.ChatId, message.Text)
+			if len(message.Buttons) > 0 {
+				botMessage.AttachInlineKeyboard(vkKeyboard(message.Buttons))
+			}
 			// Apply ParseMode if specified
 			if message.ParseMode != "" {
 				botMessage.AppendParseMode(botgolang.ParseMode(message.ParseMode))
@@ -91,3 +100,13 @@ func vkSendWithRetry(ctx context.Context, sendFunc func() error, retryCount int,
 		}
 	}
 }
+
+func vkKeyboard(buttons []Button) botgolang.Keyboard {
+	keyboard := botgolang.NewKeyboard()
+	row := make([]botgolang.Button, 0, len(buttons))
+	for _, button := range buttons {
+		row = append(row, botgolang.NewCallbackButton(button.Text, button.Command))
+	}
+	keyboard.AddRow(row...)
+	return keyboard
+}
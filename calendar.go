@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"watch_bot/ical"
+	"watch_bot/working_calendar"
+)
+
+const (
+	calendarUsage = "usage: watch_bot calendar import <file.ics|url>"
+	// calendarFetchTimeout limits the download of a calendar URL including its body
+	calendarFetchTimeout = 30 * time.Second
+	// maxCalendarSize is the number of bytes read from a calendar, national holiday calendars take a few kilobytes
+	maxCalendarSize = 10 << 20
+)
+
+// runCalendarCommand executes the `calendar` subcommand
+func runCalendarCommand(unusualDays *working_calendar.UnusualDays, args []string, out io.Writer) error {
+	if len(args) != 2 || args[0] != "import" {
+		return errors.New(calendarUsage)
+	}
+	summary, err := importCalendar(unusualDays, args[1])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "imported %s: %d added, %d updated, %d kept as entered by hand\n",
+		calendarSourceName(args[1]), summary.Added, summary.Updated, summary.Skipped)
+	return nil
+}
+
+// runCalendarImporter imports the calendar right away and then every interval until the context is cancelled.
+// A zero interval imports it once.
+func runCalendarImporter(ctx context.Context, unusualDays *working_calendar.UnusualDays, source string, interval time.Duration) {
+	logImport := func() {
+		summary, err := importCalendar(unusualDays, source)
+		if err != nil {
+			log.Printf("failed to import calendar %s: %v", calendarSourceName(source), err)
+			return
+		}
+		log.Printf("imported calendar %s: %d added, %d updated, %d kept as entered by hand",
+			calendarSourceName(source), summary.Added, summary.Updated, summary.Skipped)
+	}
+	logImport()
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			logImport()
+		}
+	}
+}
+
+// importCalendar stores the events of an iCalendar file or URL as holidays
+func importCalendar(unusualDays *working_calendar.UnusualDays, source string) (working_calendar.ImportSummary, error) {
+	body, err := openCalendar(source)
+	if err != nil {
+		return working_calendar.ImportSummary{}, err
+	}
+	defer func() {
+		if err := body.Close(); err != nil {
+			log.Printf("failed to close calendar: %v", err)
+		}
+	}()
+
+	events, err := ical.Parse(io.LimitReader(body, maxCalendarSize))
+	if err != nil {
+		return working_calendar.ImportSummary{}, fmt.Errorf("failed to parse calendar: %w", err)
+	}
+	return unusualDays.Import(working_calendar.HolidaysOf(events, calendarSourceName(source)))
+}
+
+// openCalendar downloads an http or https URL and opens anything else as a file
+func openCalendar(source string) (io.ReadCloser, error) {
+	if !isCalendarURL(source) {
+		file, err := os.Open(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open calendar: %w", err)
+		}
+		return file, nil
+	}
+
+	client := http.Client{Timeout: calendarFetchTimeout}
+	resp, err := client.Get(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download calendar: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download calendar: %s", resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// calendarSourceName names the source in logs and in the source column of the imported days.
+// URLs are reduced to the host because shared calendar links often carry a secret in the path or the query.
+func calendarSourceName(source string) string {
+	if !isCalendarURL(source) {
+		return filepath.Base(source)
+	}
+	if parsed, err := url.Parse(source); err == nil && parsed.Host != "" {
+		return parsed.Host
+	}
+	return "calendar URL"
+}
+
+func isCalendarURL(source string) bool {
+	lower := strings.ToLower(source)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
@@ -2,34 +2,36 @@ package dao
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	_ "github.com/lib/pq"
 )
 
-func getDb(connStr string) (*sql.DB, error) {
+// PoolConfig contains connection pool limits for the shared database handle
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// OpenDB creates the long-lived connection pool shared by all repositories
+func OpenDB(connStr string, config PoolConfig) (*sql.DB, error) {
 	db, err := sql.Open("postgres", connStr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database connection: %w", err)
 	}
 
+	db.SetMaxOpenConns(config.MaxOpenConns)
+	db.SetMaxIdleConns(config.MaxIdleConns)
+	db.SetConnMaxLifetime(config.ConnMaxLifetime)
+
 	return db, nil
 }
 
 // ValidateConnection verifies that the configured database is reachable.
-func ValidateConnection(connStr string) error {
-	db, err := getDb(connStr)
-	if err != nil {
-		return fmt.Errorf("failed to get db: %w", err)
-	}
-	defer func(db *sql.DB) {
-		err := db.Close()
-		if err != nil {
-			fmt.Printf("failed to close database connection: %v", err)
-		}
-	}(db)
-
+func ValidateConnection(db *sql.DB) error {
 	if err := db.Ping(); err != nil {
 		return fmt.Errorf("failed to ping database: %w", err)
 	}
@@ -37,20 +39,25 @@ func ValidateConnection(connStr string) error {
 	return nil
 }
 
-// GetUnusualDays retrieves the list of unusual days from the database.
-func GetUnusualDays(connStr string, currentDate time.Time) ([]time.Time, error) {
-	db, err := getDb(connStr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get db: %w", err)
+// PostgresRepository implements DutyRepository, CalendarRepository and TeamRepository on top of PostgreSQL.
+// Duty queries are limited to the duties of a single team.
+type PostgresRepository struct {
+	db     *sql.DB
+	teamID int64
+}
+
+// NewPostgresRepository creates a repository of the default team that uses the shared connection pool
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{
+		db:     db,
+		teamID: DefaultTeamID,
 	}
-	defer func(db *sql.DB) {
-		err := db.Close()
-		if err != nil {
-			fmt.Printf("failed to close database connection: %v", err)
-		}
-	}(db)
+}
 
-	rows, err := db.Query("select unusual_days.unusual_date from unusual_days where unusual_date >= $1", currentDate)
+// GetUnusualDays retrieves the unusual days on or after currentDate in chronological order.
+func (r *PostgresRepository) GetUnusualDays(currentDate time.Time) ([]UnusualDay, error) {
+	rows, err := r.db.Query(`select unusual_date, day_type, start_time, end_time, source from unusual_days
+where unusual_date >= $1 order by unusual_date`, currentDate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
@@ -61,10 +68,10 @@ func GetUnusualDays(connStr string, currentDate time.Time) ([]time.Time, error)
 		}
 	}(rows)
 
-	var days []time.Time
+	var days []UnusualDay
 	for rows.Next() {
-		var day time.Time
-		if err := rows.Scan(&day); err != nil {
+		var day UnusualDay
+		if err := rows.Scan(&day.Date, &day.Type, &day.StartTime, &day.EndTime, &day.Source); err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
 		days = append(days, day)
@@ -77,27 +84,103 @@ func GetUnusualDays(connStr string, currentDate time.Time) ([]time.Time, error)
 	return days, nil
 }
 
+// AddUnusualDay stores the unusual day, replacing the type and hours of an existing one on the same date.
+// It reports false when the date already was an unusual day.
+func (r *PostgresRepository) AddUnusualDay(day UnusualDay) (bool, error) {
+	var inserted bool
+	err := r.db.QueryRow(`INSERT INTO unusual_days (unusual_date, day_type, start_time, end_time, source) VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (unusual_date) DO UPDATE SET day_type = EXCLUDED.day_type, start_time = EXCLUDED.start_time, end_time = EXCLUDED.end_time,
+source = EXCLUDED.source
+RETURNING xmax = 0`, truncateToDate(day.Date), day.TypeOrDefault(), day.StartTime, day.EndTime, day.Source).Scan(&inserted)
+	if err != nil {
+		return false, fmt.Errorf("failed to add unusual day: %w", err)
+	}
+	return inserted, nil
+}
+
+// ImportUnusualDay stores an unusual day taken from an imported calendar and returns one of the
+// Import* outcomes. A day entered by hand on the same date is kept as it is.
+func (r *PostgresRepository) ImportUnusualDay(day UnusualDay) (string, error) {
+	var inserted bool
+	// the conditional update returns no row when the existing day was entered by hand
+	err := r.db.QueryRow(`INSERT INTO unusual_days (unusual_date, day_type, start_time, end_time, source) VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (unusual_date) DO UPDATE SET day_type = EXCLUDED.day_type, start_time = EXCLUDED.start_time, end_time = EXCLUDED.end_time,
+source = EXCLUDED.source WHERE unusual_days.source <> ''
+RETURNING xmax = 0`, truncateToDate(day.Date), day.TypeOrDefault(), day.StartTime, day.EndTime, day.Source).Scan(&inserted)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ImportSkipped, nil
+	case err != nil:
+		return "", fmt.Errorf("failed to import unusual day: %w", err)
+	case inserted:
+		return ImportAdded, nil
+	default:
+		return ImportUpdated, nil
+	}
+}
+
+// RemoveUnusualDay deletes the unusual day, it reports false when the date was not one
+func (r *PostgresRepository) RemoveUnusualDay(day time.Time) (bool, error) {
+	result, err := r.db.Exec("DELETE FROM unusual_days WHERE unusual_date = $1", truncateToDate(day))
+	if err != nil {
+		return false, fmt.Errorf("failed to remove unusual day: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
 // Duty represents a person on duty
 type Duty struct {
-	ID           int64
-	DutyID       string
-	LastDutyDate *time.Time
+	ID                    int64
+	TeamID                int64
+	DutyID                string
+	LastDutyDate          *time.Time
+	LastSecondaryDutyDate *time.Time
+	// ShiftStartDate is the first day of the current primary shift of the record
+	ShiftStartDate          *time.Time
+	SecondaryShiftStartDate *time.Time
+	// Position orders the rotation of the position strategy, equal positions keep the order of joining
+	Position int
+	// Weight is the share of duty of a part-time member in the weighted strategy, 1 is full time
+	Weight float64
+	// Active is false for members who left the rotation, their history is kept
+	Active bool
 }
 
-// GetAllDuties retrieves all duty records from the database
-func GetAllDuties(connStr string) ([]Duty, error) {
-	db, err := getDb(connStr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get db: %w", err)
+const dutyColumns = `id, team_id, duty_id, last_duty_date, last_secondary_duty_date, shift_start_date, secondary_shift_start_date,
+position, weight, active`
+
+// dutyDateColumn returns the duties column that tracks the rotation of role
+func dutyDateColumn(role string) string {
+	if role == RoleSecondary {
+		return "last_secondary_duty_date"
 	}
-	defer func(db *sql.DB) {
-		err := db.Close()
-		if err != nil {
-			fmt.Printf("failed to close database connection: %v", err)
-		}
-	}(db)
+	return "last_duty_date"
+}
+
+// shiftStartColumn returns the duties column that tracks the shift start of role
+func shiftStartColumn(role string) string {
+	if role == RoleSecondary {
+		return "secondary_shift_start_date"
+	}
+	return "shift_start_date"
+}
+
+// GetAllDuties retrieves the active duty records of the team ordered by duty_id
+func (r *PostgresRepository) GetAllDuties() ([]Duty, error) {
+	return r.queryDuties("SELECT "+dutyColumns+" FROM duties WHERE team_id = $1 AND active ORDER BY duty_id ASC", r.teamID)
+}
 
-	rows, err := db.Query("SELECT id, duty_id, last_duty_date FROM duties ORDER BY duty_id ASC")
+// GetMembers retrieves all duty records of the team including inactive ones ordered by duty_id
+func (r *PostgresRepository) GetMembers() ([]Duty, error) {
+	return r.queryDuties("SELECT "+dutyColumns+" FROM duties WHERE team_id = $1 ORDER BY duty_id ASC, id ASC", r.teamID)
+}
+
+func (r *PostgresRepository) queryDuties(query string, args ...any) ([]Duty, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
@@ -110,8 +193,8 @@ func GetAllDuties(connStr string) ([]Duty, error) {
 
 	var duties []Duty
 	for rows.Next() {
-		var duty Duty
-		if err := rows.Scan(&duty.ID, &duty.DutyID, &duty.LastDutyDate); err != nil {
+		duty, err := scanDuty(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
 		duties = append(duties, duty)
@@ -124,40 +207,100 @@ func GetAllDuties(connStr string) ([]Duty, error) {
 	return duties, nil
 }
 
-// UpdateDutyDate updates the last_duty_date for a duty record
-func UpdateDutyDate(connStr string, dutyID int64, date time.Time) error {
-	db, err := getDb(connStr)
+// scanDuty reads a row selected with dutyColumns
+func scanDuty(row interface{ Scan(dest ...any) error }) (Duty, error) {
+	var duty Duty
+	err := row.Scan(&duty.ID, &duty.TeamID, &duty.DutyID, &duty.LastDutyDate, &duty.LastSecondaryDutyDate,
+		&duty.ShiftStartDate, &duty.SecondaryShiftStartDate, &duty.Position, &duty.Weight, &duty.Active)
+	return duty, err
+}
+
+// GetDutyByDutyID retrieves the active duty record for dutyID, or nil if there is none
+func (r *PostgresRepository) GetDutyByDutyID(dutyID string) (*Duty, error) {
+	duty, err := scanDuty(r.db.QueryRow("SELECT "+dutyColumns+" FROM duties WHERE duty_id = $1 AND team_id = $2 AND active ORDER BY id LIMIT 1",
+		dutyID, r.teamID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
 	if err != nil {
-		return fmt.Errorf("failed to get db: %w", err)
+		return nil, fmt.Errorf("failed to get duty: %w", err)
 	}
-	defer func(db *sql.DB) {
-		err := db.Close()
-		if err != nil {
-			fmt.Printf("failed to close database connection: %v", err)
-		}
-	}(db)
+	return &duty, nil
+}
 
-	_, err = db.Exec("UPDATE duties SET last_duty_date = $1 WHERE id = $2", date, dutyID)
+// AddMember inserts an active duty record for dutyID, it is placed after everybody else in the position order
+func (r *PostgresRepository) AddMember(dutyID string) (Duty, error) {
+	duty, err := scanDuty(r.db.QueryRow(`INSERT INTO duties (duty_id, team_id, position)
+SELECT $1, $2, COALESCE(MAX(position), 0) + 1 FROM duties WHERE team_id = $2
+RETURNING `+dutyColumns, dutyID, r.teamID))
 	if err != nil {
-		return fmt.Errorf("failed to update duty date: %w", err)
+		return Duty{}, fmt.Errorf("failed to add member: %w", err)
+	}
+	return duty, nil
+}
+
+// SetActive moves a duty record of the team in or out of the rotation
+func (r *PostgresRepository) SetActive(dutyRecordID int64, active bool) error {
+	result, err := r.db.Exec("UPDATE duties SET active = $1 WHERE id = $2 AND team_id = $3", active, dutyRecordID, r.teamID)
+	if err != nil {
+		return fmt.Errorf("failed to update member: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("duty record %d not found", dutyRecordID)
 	}
 	return nil
 }
 
-// ReassignDutyDate clears the date from anyone assigned for the same day and assigns it to dutyID.
-func ReassignDutyDate(connStr string, dutyID int64, date time.Time) error {
-	db, err := getDb(connStr)
+// UpdateDutyDate updates the last duty date of the assignment role for a duty record and records the assignment in the history
+func (r *PostgresRepository) UpdateDutyDate(assignment Assignment) (err error) {
+	tx, err := r.db.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to get db: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	defer func(db *sql.DB) {
-		err := db.Close()
+	defer func() {
 		if err != nil {
-			fmt.Printf("failed to close database connection: %v", err)
+			rollbackErr := tx.Rollback()
+			if rollbackErr != nil {
+				fmt.Printf("failed to rollback transaction: %v", rollbackErr)
+			}
 		}
-	}(db)
+	}()
+
+	role := roleOf(assignment)
+	result, err := tx.Exec("UPDATE duties SET "+dutyDateColumn(role)+" = $1, "+shiftStartColumn(role)+" = $2 WHERE id = $3 AND team_id = $4",
+		assignment.Date, shiftStartOf(assignment), assignment.DutyRecordID, r.teamID)
+	if err != nil {
+		return fmt.Errorf("failed to update duty date: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		err = fmt.Errorf("duty record %d not found", assignment.DutyRecordID)
+		return err
+	}
+
+	err = insertHistory(tx, assignment, nil)
+	if err != nil {
+		return err
+	}
 
-	tx, err := db.Begin()
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// ReassignDutyDate clears the date from anyone assigned for the same day, assigns it to the new duty record
+// and records the handover in the history.
+func (r *PostgresRepository) ReassignDutyDate(assignment Assignment) (err error) {
+	tx, err := r.db.Begin()
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -170,12 +313,21 @@ func ReassignDutyDate(connStr string, dutyID int64, date time.Time) error {
 		}
 	}()
 
-	_, err = tx.Exec("UPDATE duties SET last_duty_date = NULL WHERE last_duty_date = $1", date)
+	column := dutyDateColumn(roleOf(assignment))
+	var replacedID *int64
+	err = tx.QueryRow("SELECT id FROM duties WHERE "+column+" = $1 AND team_id = $2 ORDER BY id LIMIT 1", assignment.Date, r.teamID).
+		Scan(&replacedID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to find current duty: %w", err)
+	}
+
+	_, err = tx.Exec("UPDATE duties SET "+column+" = NULL WHERE "+column+" = $1 AND team_id = $2", assignment.Date, r.teamID)
 	if err != nil {
 		return fmt.Errorf("failed to clear duty dates: %w", err)
 	}
 
-	result, err := tx.Exec("UPDATE duties SET last_duty_date = $1 WHERE id = $2", date, dutyID)
+	result, err := tx.Exec("UPDATE duties SET "+column+" = $1, "+shiftStartColumn(roleOf(assignment))+" = $2 WHERE id = $3 AND team_id = $4",
+		assignment.Date, shiftStartOf(assignment), assignment.DutyRecordID, r.teamID)
 	if err != nil {
 		return fmt.Errorf("failed to update duty date: %w", err)
 	}
@@ -184,7 +336,12 @@ func ReassignDutyDate(connStr string, dutyID int64, date time.Time) error {
 		return fmt.Errorf("failed to get rows affected: %w", err)
 	}
 	if rowsAffected == 0 {
-		err = fmt.Errorf("duty record %d not found", dutyID)
+		err = fmt.Errorf("duty record %d not found", assignment.DutyRecordID)
+		return err
+	}
+
+	err = insertHistory(tx, assignment, replacedID)
+	if err != nil {
 		return err
 	}
 
@@ -194,3 +351,265 @@ func ReassignDutyDate(connStr string, dutyID int64, date time.Time) error {
 	}
 	return nil
 }
+
+// insertHistory records the assignment. The person the caller replaced is kept, otherwise a substitute
+// replaces the rotation pick.
+func insertHistory(tx *sql.Tx, assignment Assignment, replacedID *int64) error {
+	onDutyID := assignment.DutyRecordID
+	if assignment.SubstituteRecordID != 0 && assignment.SubstituteRecordID != assignment.DutyRecordID {
+		onDutyID = assignment.SubstituteRecordID
+		if replacedID == nil {
+			replacedID = &assignment.DutyRecordID
+		}
+	}
+	_, err := tx.Exec(`INSERT INTO duty_history (duty_record_id, duty_date, replaced_duty_record_id, actor_user_id, reason, role)
+VALUES ($1, $2, $3, $4, $5, $6)`, onDutyID, assignment.Date, replacedID, assignment.ActorUserID, assignment.Reason, roleOf(assignment))
+	if err != nil {
+		return fmt.Errorf("failed to insert duty history: %w", err)
+	}
+	return nil
+}
+
+// GetDutyHistory retrieves history entries with duty_date >= since, most recent first
+func (r *PostgresRepository) GetDutyHistory(since time.Time) ([]HistoryEntry, error) {
+	rows, err := r.db.Query(`SELECT h.id, h.duty_record_id, d.duty_id, h.duty_date, coalesce(rd.duty_id, ''),
+       h.actor_user_id, h.reason, h.role, h.created_at
+FROM duty_history h
+         JOIN duties d ON d.id = h.duty_record_id
+         LEFT JOIN duties rd ON rd.id = h.replaced_duty_record_id
+WHERE h.duty_date >= $1 AND d.team_id = $2
+ORDER BY h.duty_date DESC, h.id DESC`, since, r.teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			fmt.Printf("failed to close rows: %v", err)
+		}
+	}(rows)
+
+	var entries []HistoryEntry
+	for rows.Next() {
+		var entry HistoryEntry
+		if err := rows.Scan(&entry.ID, &entry.DutyRecordID, &entry.DutyID, &entry.DutyDate, &entry.ReplacedDutyID,
+			&entry.ActorUserID, &entry.Reason, &entry.Role, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during row iteration: %w", err)
+	}
+
+	return entries, nil
+}
+
+// GetAbsences retrieves absences overlapping the period between from and to inclusive
+func (r *PostgresRepository) GetAbsences(from, to time.Time) ([]Absence, error) {
+	rows, err := r.db.Query(`SELECT a.id, a.duty_record_id, a.from_date, a.to_date, a.reason
+FROM duty_absences a
+         JOIN duties d ON d.id = a.duty_record_id
+WHERE a.from_date <= $2 AND a.to_date >= $1 AND d.team_id = $3
+ORDER BY a.from_date, a.id`, from, to, r.teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			fmt.Printf("failed to close rows: %v", err)
+		}
+	}(rows)
+
+	var absences []Absence
+	for rows.Next() {
+		var absence Absence
+		if err := rows.Scan(&absence.ID, &absence.DutyRecordID, &absence.From, &absence.To, &absence.Reason); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		absences = append(absences, absence)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during row iteration: %w", err)
+	}
+
+	return absences, nil
+}
+
+// AddAbsence stores a new absence and returns it with the assigned ID
+func (r *PostgresRepository) AddAbsence(absence Absence) (Absence, error) {
+	err := r.db.QueryRow("INSERT INTO duty_absences (duty_record_id, from_date, to_date, reason) VALUES ($1, $2, $3, $4) RETURNING id",
+		absence.DutyRecordID, absence.From, absence.To, absence.Reason).Scan(&absence.ID)
+	if err != nil {
+		return Absence{}, fmt.Errorf("failed to insert absence: %w", err)
+	}
+	return absence, nil
+}
+
+// EndAbsences removes future absences of a duty record and shortens the one covering date so that it ends the day before
+func (r *PostgresRepository) EndAbsences(dutyRecordID int64, date time.Time) (changed int64, err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rollbackErr := tx.Rollback()
+			if rollbackErr != nil {
+				fmt.Printf("failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	deleted, err := tx.Exec("DELETE FROM duty_absences WHERE duty_record_id = $1 AND from_date >= $2", dutyRecordID, date)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete absences: %w", err)
+	}
+	shortened, err := tx.Exec("UPDATE duty_absences SET to_date = $2::date - 1 WHERE duty_record_id = $1 AND from_date < $2 AND to_date >= $2",
+		dutyRecordID, date)
+	if err != nil {
+		return 0, fmt.Errorf("failed to shorten absences: %w", err)
+	}
+
+	deletedCount, err := deleted.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	shortenedCount, err := shortened.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return deletedCount + shortenedCount, nil
+}
+
+const swapColumns = `s.id, s.swap_date, s.requester_duty_record_id, rd.duty_id, s.target_duty_record_id, td.duty_id, s.status, s.created_at
+FROM duty_swaps s
+         JOIN duties rd ON rd.id = s.requester_duty_record_id
+         JOIN duties td ON td.id = s.target_duty_record_id`
+
+func (r *PostgresRepository) querySwaps(query string, args ...any) ([]Swap, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			fmt.Printf("failed to close rows: %v", err)
+		}
+	}(rows)
+
+	var swaps []Swap
+	for rows.Next() {
+		var swap Swap
+		if err := rows.Scan(&swap.ID, &swap.Date, &swap.RequesterRecordID, &swap.RequesterDutyID, &swap.TargetRecordID,
+			&swap.TargetDutyID, &swap.Status, &swap.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		swaps = append(swaps, swap)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during row iteration: %w", err)
+	}
+
+	return swaps, nil
+}
+
+// CreateSwap stores a new pending swap and returns it with the assigned ID
+func (r *PostgresRepository) CreateSwap(swap Swap) (Swap, error) {
+	err := r.db.QueryRow(`INSERT INTO duty_swaps (swap_date, requester_duty_record_id, target_duty_record_id, status)
+VALUES ($1, $2, $3, $4) RETURNING id, created_at`, swap.Date, swap.RequesterRecordID, swap.TargetRecordID, SwapPending).
+		Scan(&swap.ID, &swap.CreatedAt)
+	if err != nil {
+		return Swap{}, fmt.Errorf("failed to insert swap: %w", err)
+	}
+	swap.Status = SwapPending
+	return swap, nil
+}
+
+// GetPendingSwaps retrieves pending swaps addressed to the target record, newest first
+func (r *PostgresRepository) GetPendingSwaps(targetRecordID int64) ([]Swap, error) {
+	return r.querySwaps("SELECT "+swapColumns+`
+WHERE s.target_duty_record_id = $1 AND s.status = $2
+ORDER BY s.id DESC`, targetRecordID, SwapPending)
+}
+
+// GetAcceptedSwaps retrieves accepted swaps with dates between from and to inclusive
+func (r *PostgresRepository) GetAcceptedSwaps(from, to time.Time) ([]Swap, error) {
+	return r.querySwaps("SELECT "+swapColumns+`
+WHERE s.swap_date BETWEEN $1 AND $2 AND s.status = $3 AND rd.team_id = $4
+ORDER BY s.swap_date, s.id`, from, to, SwapAccepted, r.teamID)
+}
+
+// ResolveSwap moves a pending swap to the given status
+func (r *PostgresRepository) ResolveSwap(swapID int64, status string) error {
+	result, err := r.db.Exec("UPDATE duty_swaps SET status = $2, resolved_at = now() WHERE id = $1 AND status = $3",
+		swapID, status, SwapPending)
+	if err != nil {
+		return fmt.Errorf("failed to resolve swap: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("pending swap %d not found", swapID)
+	}
+	return nil
+}
+
+// RecordPage stores a call of a duty person
+func (r *PostgresRepository) RecordPage(page Page) error {
+	role := page.Role
+	if role == "" {
+		role = RolePrimary
+	}
+	_, err := r.db.Exec("INSERT INTO duty_pages (duty_record_id, paged_at, actor_user_id, role) VALUES ($1, $2, $3, $4)",
+		page.DutyRecordID, page.PagedAt, page.ActorUserID, role)
+	if err != nil {
+		return fmt.Errorf("failed to insert page: %w", err)
+	}
+	return nil
+}
+
+// GetPages retrieves the pages of the team made since the given time, oldest first
+func (r *PostgresRepository) GetPages(since time.Time) ([]Page, error) {
+	rows, err := r.db.Query(`SELECT p.id, p.duty_record_id, d.duty_id, p.paged_at, p.actor_user_id, p.role
+FROM duty_pages p
+         JOIN duties d ON d.id = p.duty_record_id
+WHERE p.paged_at >= $1 AND d.team_id = $2
+ORDER BY p.paged_at, p.id`, since, r.teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			fmt.Printf("failed to close rows: %v", err)
+		}
+	}(rows)
+
+	var pages []Page
+	for rows.Next() {
+		var page Page
+		if err := rows.Scan(&page.ID, &page.DutyRecordID, &page.DutyID, &page.PagedAt, &page.ActorUserID, &page.Role); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		pages = append(pages, page)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during row iteration: %w", err)
+	}
+
+	return pages, nil
+}
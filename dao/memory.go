@@ -0,0 +1,546 @@
+package dao
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryRepository is an in-memory implementation of DutyRepository, CalendarRepository and TeamRepository.
+// It mirrors the behaviour of PostgresRepository and is intended for tests and local runs.
+type MemoryRepository struct {
+	*memoryStore
+	teamID int64
+}
+
+// memoryStore holds the data shared by the repositories of all teams
+type memoryStore struct {
+	mu            sync.Mutex
+	teams         []Team
+	nextTeamID    int64
+	duties        []Duty
+	nextID        int64
+	history       []HistoryEntry
+	nextHistoryID int64
+	absences      []Absence
+	nextAbsenceID int64
+	swaps         []Swap
+	nextSwapID    int64
+	pages         []Page
+	nextPageID    int64
+	unusualDays   []UnusualDay
+}
+
+// NewMemoryRepository creates an in-memory repository of the default team with no duties
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		memoryStore: &memoryStore{
+			teams:         []Team{{ID: DefaultTeamID, Name: "default"}},
+			nextTeamID:    DefaultTeamID + 1,
+			nextID:        1,
+			nextHistoryID: 1,
+			nextAbsenceID: 1,
+			nextSwapID:    1,
+			nextPageID:    1,
+		},
+		teamID: DefaultTeamID,
+	}
+}
+
+// ForTeam returns a repository that shares the data and sees only the duties of teamID
+func (r *MemoryRepository) ForTeam(teamID int64) *MemoryRepository {
+	return &MemoryRepository{
+		memoryStore: r.memoryStore,
+		teamID:      teamID,
+	}
+}
+
+// AddTeam inserts a new team and returns it with the assigned ID
+func (r *MemoryRepository) AddTeam(team Team) Team {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	team.ID = r.nextTeamID
+	r.nextTeamID++
+	r.teams = append(r.teams, team)
+	return team
+}
+
+// GetTeams returns all teams ordered by ID
+func (r *MemoryRepository) GetTeams() ([]Team, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Team(nil), r.teams...), nil
+}
+
+// AddDuty inserts a new duty record of the repository team and returns it with the assigned ID
+func (r *MemoryRepository) AddDuty(dutyID string, lastDutyDate *time.Time) Duty {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	duty := Duty{
+		ID:             r.nextID,
+		TeamID:         r.teamID,
+		DutyID:         dutyID,
+		LastDutyDate:   copyDate(lastDutyDate),
+		ShiftStartDate: copyDate(lastDutyDate),
+		Weight:         1,
+		Active:         true,
+	}
+	r.nextID++
+	r.duties = append(r.duties, duty)
+	return duty
+}
+
+// AddUnusualDay stores the unusual day, replacing the type and hours of an existing one on the same date.
+// It reports false when the date already was an unusual day.
+func (r *MemoryRepository) AddUnusualDay(day UnusualDay) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	day.Date = truncateToDate(day.Date)
+	day.Type = day.TypeOrDefault()
+	for i, existing := range r.unusualDays {
+		if existing.Date.Equal(day.Date) {
+			r.unusualDays[i] = day
+			return false, nil
+		}
+	}
+	r.unusualDays = append(r.unusualDays, day)
+	return true, nil
+}
+
+// ImportUnusualDay stores an unusual day taken from an imported calendar and returns one of the
+// Import* outcomes. A day entered by hand on the same date is kept as it is.
+func (r *MemoryRepository) ImportUnusualDay(day UnusualDay) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	day.Date = truncateToDate(day.Date)
+	day.Type = day.TypeOrDefault()
+	for i, existing := range r.unusualDays {
+		if existing.Date.Equal(day.Date) {
+			if existing.Source == "" {
+				return ImportSkipped, nil
+			}
+			r.unusualDays[i] = day
+			return ImportUpdated, nil
+		}
+	}
+	r.unusualDays = append(r.unusualDays, day)
+	return ImportAdded, nil
+}
+
+// RemoveUnusualDay deletes the unusual day, it reports false when the date was not one
+func (r *MemoryRepository) RemoveUnusualDay(day time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	day = truncateToDate(day)
+	for i, existing := range r.unusualDays {
+		if existing.Date.Equal(day) {
+			r.unusualDays = append(r.unusualDays[:i], r.unusualDays[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetUnusualDays returns the unusual days on or after currentDate in chronological order
+func (r *MemoryRepository) GetUnusualDays(currentDate time.Time) ([]UnusualDay, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var days []UnusualDay
+	for _, day := range r.unusualDays {
+		if !day.Date.Before(currentDate) {
+			days = append(days, day)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Date.Before(days[j].Date)
+	})
+	return days, nil
+}
+
+// GetAllDuties returns copies of the active duty records ordered by duty_id
+func (r *MemoryRepository) GetAllDuties() ([]Duty, error) {
+	return r.members(true), nil
+}
+
+// GetMembers returns copies of all duty records including inactive ones ordered by duty_id
+func (r *MemoryRepository) GetMembers() ([]Duty, error) {
+	return r.members(false), nil
+}
+
+func (r *MemoryRepository) members(activeOnly bool) []Duty {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	duties := make([]Duty, 0, len(r.duties))
+	for _, duty := range r.duties {
+		if duty.TeamID != r.teamID || activeOnly && !duty.Active {
+			continue
+		}
+		duties = append(duties, copyDuty(duty))
+	}
+	sort.SliceStable(duties, func(i, j int) bool {
+		return duties[i].DutyID < duties[j].DutyID
+	})
+	return duties
+}
+
+// GetDutyByDutyID returns a copy of the active duty record for dutyID, or nil if there is none
+func (r *MemoryRepository) GetDutyByDutyID(dutyID string) (*Duty, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, duty := range r.duties {
+		if duty.DutyID == dutyID && duty.TeamID == r.teamID && duty.Active {
+			duty = copyDuty(duty)
+			return &duty, nil
+		}
+	}
+	return nil, nil
+}
+
+// AddMember inserts an active duty record for dutyID after everybody else in the position order
+func (r *MemoryRepository) AddMember(dutyID string) (Duty, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	position := 0
+	for _, duty := range r.duties {
+		if duty.TeamID == r.teamID && duty.Position > position {
+			position = duty.Position
+		}
+	}
+	duty := Duty{
+		ID:       r.nextID,
+		TeamID:   r.teamID,
+		DutyID:   dutyID,
+		Position: position + 1,
+		Weight:   1,
+		Active:   true,
+	}
+	r.nextID++
+	r.duties = append(r.duties, duty)
+	return duty, nil
+}
+
+// SetActive moves a duty record of the team in or out of the rotation
+func (r *MemoryRepository) SetActive(dutyRecordID int64, active bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.inTeam(dutyRecordID) {
+		return fmt.Errorf("duty record %d not found", dutyRecordID)
+	}
+	r.duties[r.indexOf(dutyRecordID)].Active = active
+	return nil
+}
+
+// UpdateDutyDate updates the last duty date of the assignment role for a duty record and records the assignment in the history
+func (r *MemoryRepository) UpdateDutyDate(assignment Assignment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.inTeam(assignment.DutyRecordID) {
+		return fmt.Errorf("duty record %d not found", assignment.DutyRecordID)
+	}
+	r.assign(&r.duties[r.indexOf(assignment.DutyRecordID)], assignment)
+	r.appendHistory(assignment, "")
+	return nil
+}
+
+// ReassignDutyDate clears the date from anyone assigned for the same day, assigns it to the new duty record
+// and records the handover in the history.
+func (r *MemoryRepository) ReassignDutyDate(assignment Assignment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.inTeam(assignment.DutyRecordID) {
+		return fmt.Errorf("duty record %d not found", assignment.DutyRecordID)
+	}
+	target := r.indexOf(assignment.DutyRecordID)
+
+	role := roleOf(assignment)
+	replacedDutyID := ""
+	for i := range r.duties {
+		if r.duties[i].TeamID != r.teamID {
+			continue
+		}
+		date := dutyDateOf(&r.duties[i], role)
+		if *date != nil && (*date).Equal(assignment.Date) {
+			if replacedDutyID == "" {
+				replacedDutyID = r.duties[i].DutyID
+			}
+			*date = nil
+		}
+	}
+	r.assign(&r.duties[target], assignment)
+	r.appendHistory(assignment, replacedDutyID)
+	return nil
+}
+
+// GetDutyHistory returns history entries with duty_date >= since, most recent first
+func (r *MemoryRepository) GetDutyHistory(since time.Time) ([]HistoryEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var entries []HistoryEntry
+	for i := len(r.history) - 1; i >= 0; i-- {
+		if !r.history[i].DutyDate.Before(since) && r.inTeam(r.history[i].DutyRecordID) {
+			entries = append(entries, r.history[i])
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].DutyDate.After(entries[j].DutyDate)
+	})
+	return entries, nil
+}
+
+// GetAbsences returns absences overlapping the period between from and to inclusive
+func (r *MemoryRepository) GetAbsences(from, to time.Time) ([]Absence, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var absences []Absence
+	for _, absence := range r.absences {
+		if !r.inTeam(absence.DutyRecordID) {
+			continue
+		}
+		if !truncateToDate(absence.From).After(truncateToDate(to)) && !truncateToDate(absence.To).Before(truncateToDate(from)) {
+			absences = append(absences, absence)
+		}
+	}
+	return absences, nil
+}
+
+// AddAbsence stores a new absence and returns it with the assigned ID
+func (r *MemoryRepository) AddAbsence(absence Absence) (Absence, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.indexOf(absence.DutyRecordID) == -1 {
+		return Absence{}, fmt.Errorf("duty record %d not found", absence.DutyRecordID)
+	}
+	if absence.To.Before(absence.From) {
+		return Absence{}, fmt.Errorf("absence ends before it starts")
+	}
+	absence.ID = r.nextAbsenceID
+	r.nextAbsenceID++
+	r.absences = append(r.absences, absence)
+	return absence, nil
+}
+
+// EndAbsences removes future absences of a duty record and shortens the one covering date so that it ends the day before
+func (r *MemoryRepository) EndAbsences(dutyRecordID int64, date time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	day := truncateToDate(date)
+	var changed int64
+	kept := r.absences[:0]
+	for _, absence := range r.absences {
+		if absence.DutyRecordID == dutyRecordID {
+			if !truncateToDate(absence.From).Before(day) {
+				changed++
+				continue
+			}
+			if !truncateToDate(absence.To).Before(day) {
+				absence.To = day.AddDate(0, 0, -1)
+				changed++
+			}
+		}
+		kept = append(kept, absence)
+	}
+	r.absences = kept
+	return changed, nil
+}
+
+func (r *MemoryRepository) indexOf(dutyRecordID int64) int {
+	for i := range r.duties {
+		if r.duties[i].ID == dutyRecordID {
+			return i
+		}
+	}
+	return -1
+}
+
+// inTeam reports whether the duty record belongs to the repository team
+func (r *MemoryRepository) inTeam(dutyRecordID int64) bool {
+	i := r.indexOf(dutyRecordID)
+	return i != -1 && r.duties[i].TeamID == r.teamID
+}
+
+// appendHistory records the assignment. The person the caller replaced is kept, otherwise a substitute
+// replaces the rotation pick.
+func (r *MemoryRepository) appendHistory(assignment Assignment, replacedDutyID string) {
+	onDutyID := assignment.DutyRecordID
+	if assignment.SubstituteRecordID != 0 && assignment.SubstituteRecordID != assignment.DutyRecordID {
+		onDutyID = assignment.SubstituteRecordID
+		if replacedDutyID == "" {
+			replacedDutyID = r.dutyIDOf(assignment.DutyRecordID)
+		}
+	}
+	entry := HistoryEntry{
+		ID:             r.nextHistoryID,
+		DutyRecordID:   onDutyID,
+		DutyDate:       assignment.Date,
+		ReplacedDutyID: replacedDutyID,
+		ActorUserID:    assignment.ActorUserID,
+		Reason:         assignment.Reason,
+		Role:           roleOf(assignment),
+		CreatedAt:      time.Now(),
+	}
+	entry.DutyID = r.dutyIDOf(onDutyID)
+	r.nextHistoryID++
+	r.history = append(r.history, entry)
+}
+
+// dutyDateOf returns the field that tracks the rotation of role
+// assign puts the duty record on duty for the assignment date within the assignment shift
+func (r *MemoryRepository) assign(duty *Duty, assignment Assignment) {
+	role := roleOf(assignment)
+	shiftStart := shiftStartOf(assignment)
+	*dutyDateOf(duty, role) = copyDate(&assignment.Date)
+	if role == RoleSecondary {
+		duty.SecondaryShiftStartDate = copyDate(&shiftStart)
+	} else {
+		duty.ShiftStartDate = copyDate(&shiftStart)
+	}
+}
+
+func dutyDateOf(duty *Duty, role string) **time.Time {
+	if role == RoleSecondary {
+		return &duty.LastSecondaryDutyDate
+	}
+	return &duty.LastDutyDate
+}
+
+func (r *MemoryRepository) dutyIDOf(dutyRecordID int64) string {
+	if i := r.indexOf(dutyRecordID); i != -1 {
+		return r.duties[i].DutyID
+	}
+	return ""
+}
+
+// CreateSwap stores a new pending swap and returns it with the assigned ID
+func (r *MemoryRepository) CreateSwap(swap Swap) (Swap, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.indexOf(swap.RequesterRecordID) == -1 || r.indexOf(swap.TargetRecordID) == -1 {
+		return Swap{}, fmt.Errorf("duty record not found")
+	}
+	swap.ID = r.nextSwapID
+	swap.Status = SwapPending
+	swap.CreatedAt = time.Now()
+	r.nextSwapID++
+	r.swaps = append(r.swaps, swap)
+	return r.withDutyIDs(swap), nil
+}
+
+// GetPendingSwaps returns pending swaps addressed to the target record, newest first
+func (r *MemoryRepository) GetPendingSwaps(targetRecordID int64) ([]Swap, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var swaps []Swap
+	for i := len(r.swaps) - 1; i >= 0; i-- {
+		if r.swaps[i].TargetRecordID == targetRecordID && r.swaps[i].Status == SwapPending {
+			swaps = append(swaps, r.withDutyIDs(r.swaps[i]))
+		}
+	}
+	return swaps, nil
+}
+
+// GetAcceptedSwaps returns accepted swaps with dates between from and to inclusive
+func (r *MemoryRepository) GetAcceptedSwaps(from, to time.Time) ([]Swap, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var swaps []Swap
+	for _, swap := range r.swaps {
+		day := truncateToDate(swap.Date)
+		if swap.Status == SwapAccepted && r.inTeam(swap.RequesterRecordID) && !day.Before(truncateToDate(from)) && !day.After(truncateToDate(to)) {
+			swaps = append(swaps, r.withDutyIDs(swap))
+		}
+	}
+	return swaps, nil
+}
+
+// ResolveSwap moves a pending swap to the given status
+func (r *MemoryRepository) ResolveSwap(swapID int64, status string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.swaps {
+		if r.swaps[i].ID == swapID && r.swaps[i].Status == SwapPending {
+			r.swaps[i].Status = status
+			return nil
+		}
+	}
+	return fmt.Errorf("pending swap %d not found", swapID)
+}
+
+// RecordPage stores a call of a duty person
+func (r *MemoryRepository) RecordPage(page Page) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.indexOf(page.DutyRecordID) == -1 {
+		return fmt.Errorf("duty record %d not found", page.DutyRecordID)
+	}
+	if page.Role == "" {
+		page.Role = RolePrimary
+	}
+	page.ID = r.nextPageID
+	r.nextPageID++
+	r.pages = append(r.pages, page)
+	return nil
+}
+
+// GetPages returns the pages of the team made since the given time, oldest first
+func (r *MemoryRepository) GetPages(since time.Time) ([]Page, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var pages []Page
+	for _, page := range r.pages {
+		if !page.PagedAt.Before(since) && r.inTeam(page.DutyRecordID) {
+			page.DutyID = r.dutyIDOf(page.DutyRecordID)
+			pages = append(pages, page)
+		}
+	}
+	sort.SliceStable(pages, func(i, j int) bool {
+		return pages[i].PagedAt.Before(pages[j].PagedAt)
+	})
+	return pages, nil
+}
+
+func (r *MemoryRepository) withDutyIDs(swap Swap) Swap {
+	swap.RequesterDutyID = r.dutyIDOf(swap.RequesterRecordID)
+	swap.TargetDutyID = r.dutyIDOf(swap.TargetRecordID)
+	return swap
+}
+
+// copyDuty returns the duty with its dates copied, so callers cannot change the stored record
+func copyDuty(duty Duty) Duty {
+	duty.LastDutyDate = copyDate(duty.LastDutyDate)
+	duty.LastSecondaryDutyDate = copyDate(duty.LastSecondaryDutyDate)
+	duty.ShiftStartDate = copyDate(duty.ShiftStartDate)
+	duty.SecondaryShiftStartDate = copyDate(duty.SecondaryShiftStartDate)
+	return duty
+}
+
+func copyDate(date *time.Time) *time.Time {
+	if date == nil {
+		return nil
+	}
+	value := *date
+	return &value
+}
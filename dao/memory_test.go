@@ -0,0 +1,298 @@
+package dao
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryRepository_GetAllDutiesSortedByDutyID(t *testing.T) {
+	repo := NewMemoryRepository()
+	repo.AddDuty("charlie", nil)
+	repo.AddDuty("alice", nil)
+	repo.AddDuty("bob", nil)
+
+	duties, err := repo.GetAllDuties()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(duties) != 3 {
+		t.Fatalf("expected 3 duties, got %d", len(duties))
+	}
+	for i, expected := range []string{"alice", "bob", "charlie"} {
+		if duties[i].DutyID != expected {
+			t.Errorf("expected duty %d to be %s, got %s", i, expected, duties[i].DutyID)
+		}
+	}
+}
+
+func TestMemoryRepository_GetAllDutiesReturnsCopies(t *testing.T) {
+	repo := NewMemoryRepository()
+	yesterday := time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC)
+	repo.AddDuty("alice", &yesterday)
+
+	duties, _ := repo.GetAllDuties()
+	*duties[0].LastDutyDate = yesterday.AddDate(0, 0, 5)
+
+	duties, _ = repo.GetAllDuties()
+	if !duties[0].LastDutyDate.Equal(yesterday) {
+		t.Errorf("expected stored date to stay %v, got %v", yesterday, *duties[0].LastDutyDate)
+	}
+}
+
+func TestMemoryRepository_ReassignDutyDate(t *testing.T) {
+	repo := NewMemoryRepository()
+	today := time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC)
+	alice := repo.AddDuty("alice", &today)
+	bob := repo.AddDuty("bob", nil)
+
+	if err := repo.ReassignDutyDate(Assignment{DutyRecordID: bob.ID, Date: today, ActorUserID: "admin", Reason: ReasonNext}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	duties, _ := repo.GetAllDuties()
+	for _, duty := range duties {
+		switch duty.ID {
+		case alice.ID:
+			if duty.LastDutyDate != nil {
+				t.Errorf("expected alice's date to be cleared, got %v", *duty.LastDutyDate)
+			}
+		case bob.ID:
+			if duty.LastDutyDate == nil || !duty.LastDutyDate.Equal(today) {
+				t.Errorf("expected bob to be assigned %v, got %v", today, duty.LastDutyDate)
+			}
+		}
+	}
+}
+
+func TestMemoryRepository_SecondaryRoleUsesOwnRotation(t *testing.T) {
+	repo := NewMemoryRepository()
+	today := time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC)
+	alice := repo.AddDuty("alice", &today)
+	repo.AddDuty("bob", nil)
+
+	if err := repo.UpdateDutyDate(Assignment{DutyRecordID: alice.ID, Date: today, Reason: ReasonRotation, Role: RoleSecondary}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	duty, _ := repo.GetDutyByDutyID("alice")
+	if duty.LastDutyDate == nil || !duty.LastDutyDate.Equal(today) {
+		t.Errorf("expected primary date to be kept, got %v", duty.LastDutyDate)
+	}
+	if duty.LastSecondaryDutyDate == nil || !duty.LastSecondaryDutyDate.Equal(today) {
+		t.Errorf("expected secondary date %v, got %v", today, duty.LastSecondaryDutyDate)
+	}
+	entries, _ := repo.GetDutyHistory(today)
+	if len(entries) != 1 || entries[0].Role != RoleSecondary {
+		t.Errorf("expected a secondary history entry, got %+v", entries)
+	}
+}
+
+func TestMemoryRepository_HistoryRecordsAssignments(t *testing.T) {
+	repo := NewMemoryRepository()
+	yesterday := time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC)
+	today := yesterday.AddDate(0, 0, 1)
+	alice := repo.AddDuty("alice", nil)
+	bob := repo.AddDuty("bob", nil)
+
+	_ = repo.UpdateDutyDate(Assignment{DutyRecordID: bob.ID, Date: yesterday, ActorUserID: "caller", Reason: ReasonRotation})
+	_ = repo.UpdateDutyDate(Assignment{DutyRecordID: alice.ID, Date: today, ActorUserID: "caller", Reason: ReasonRotation})
+	_ = repo.ReassignDutyDate(Assignment{DutyRecordID: bob.ID, Date: today, ActorUserID: "admin", Reason: ReasonNext})
+
+	entries, err := repo.GetDutyHistory(yesterday)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 history entries, got %d", len(entries))
+	}
+	latest := entries[0]
+	if latest.DutyID != "bob" || latest.ReplacedDutyID != "alice" || latest.Reason != ReasonNext || latest.ActorUserID != "admin" {
+		t.Errorf("unexpected latest entry: %+v", latest)
+	}
+	if entries[2].DutyID != "bob" || !entries[2].DutyDate.Equal(yesterday) {
+		t.Errorf("expected oldest entry to be bob yesterday, got %+v", entries[2])
+	}
+
+	entries, _ = repo.GetDutyHistory(today)
+	if len(entries) != 2 {
+		t.Errorf("expected 2 entries since today, got %d", len(entries))
+	}
+}
+
+func TestMemoryRepository_ReassignDutyDateUnknownRecord(t *testing.T) {
+	repo := NewMemoryRepository()
+	today := time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC)
+	alice := repo.AddDuty("alice", &today)
+
+	if err := repo.ReassignDutyDate(Assignment{DutyRecordID: alice.ID + 100, Date: today, Reason: ReasonNext}); err == nil {
+		t.Fatal("expected error for unknown duty record")
+	}
+
+	duties, _ := repo.GetAllDuties()
+	if duties[0].LastDutyDate == nil {
+		t.Error("expected existing assignment to be kept when reassignment fails")
+	}
+}
+
+func TestMemoryRepository_GetUnusualDays(t *testing.T) {
+	repo := NewMemoryRepository()
+	repo.AddUnusualDay(UnusualDay{Date: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)})
+	repo.AddUnusualDay(UnusualDay{Date: time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC)})
+
+	days, err := repo.GetUnusualDays(time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(days) != 1 || days[0].Date.Day() != 9 || days[0].Type != UnusualDayInverted {
+		t.Errorf("expected only 2026-01-09, got %v", days)
+	}
+}
+
+func TestMemoryRepository_TeamsAreIsolated(t *testing.T) {
+	repo := NewMemoryRepository()
+	payments := repo.AddTeam(Team{Name: "payments", MainChatId: "payments-main"})
+	paymentsRepo := repo.ForTeam(payments.ID)
+
+	alice := repo.AddDuty("alice", nil)
+	bob := paymentsRepo.AddDuty("bob", nil)
+	paymentsRepo.AddDuty("alice", nil)
+
+	today := time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC)
+	if err := repo.UpdateDutyDate(Assignment{DutyRecordID: alice.ID, Date: today}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := paymentsRepo.UpdateDutyDate(Assignment{DutyRecordID: bob.ID, Date: today}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := paymentsRepo.AddAbsence(Absence{DutyRecordID: bob.ID, From: today, To: today}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	duties, _ := repo.GetAllDuties()
+	if len(duties) != 1 || duties[0].DutyID != "alice" || duties[0].TeamID != DefaultTeamID {
+		t.Errorf("expected only alice in the default team, got %+v", duties)
+	}
+	duties, _ = paymentsRepo.GetAllDuties()
+	if len(duties) != 2 || duties[0].TeamID != payments.ID {
+		t.Errorf("expected alice and bob in payments, got %+v", duties)
+	}
+
+	duty, _ := paymentsRepo.GetDutyByDutyID("alice")
+	if duty == nil || duty.ID == alice.ID {
+		t.Errorf("expected the payments record of alice, got %+v", duty)
+	}
+
+	history, _ := repo.GetDutyHistory(today)
+	if len(history) != 1 || history[0].DutyID != "alice" {
+		t.Errorf("expected only the default team history, got %+v", history)
+	}
+	absences, _ := repo.GetAbsences(today, today)
+	if len(absences) != 0 {
+		t.Errorf("expected no absences in the default team, got %+v", absences)
+	}
+	absences, _ = paymentsRepo.GetAbsences(today, today)
+	if len(absences) != 1 {
+		t.Errorf("expected the absence of bob in payments, got %+v", absences)
+	}
+
+	teams, _ := repo.GetTeams()
+	if len(teams) != 2 || teams[0].ID != DefaultTeamID || teams[1].Name != "payments" {
+		t.Errorf("unexpected teams %+v", teams)
+	}
+}
+
+func TestMemoryRepository_DutyDatesOfAnotherTeam(t *testing.T) {
+	repo := NewMemoryRepository()
+	alice := repo.AddDuty("alice", nil)
+	other := repo.ForTeam(2)
+	today := time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC)
+
+	if err := other.UpdateDutyDate(Assignment{DutyRecordID: alice.ID, Date: today}); err == nil {
+		t.Error("expected an error when updating a record of another team")
+	}
+	if err := other.ReassignDutyDate(Assignment{DutyRecordID: alice.ID, Date: today}); err == nil {
+		t.Error("expected an error when reassigning to a record of another team")
+	}
+	if duty, _ := repo.GetDutyByDutyID("alice"); duty == nil || duty.LastDutyDate != nil {
+		t.Errorf("expected alice to stay unassigned, got %+v", duty)
+	}
+	if history, _ := repo.GetDutyHistory(time.Time{}); len(history) != 0 {
+		t.Errorf("expected no history, got %+v", history)
+	}
+}
+
+func TestMemoryRepository_InactiveMembersLeaveRotation(t *testing.T) {
+	repo := NewMemoryRepository()
+	alice := repo.AddDuty("alice", nil)
+	bob, err := repo.AddMember("bob")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if bob.Position != 1 || !bob.Active {
+		t.Errorf("expected bob to be active after everybody else, got %+v", bob)
+	}
+
+	if err := repo.SetActive(alice.ID, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	duties, _ := repo.GetAllDuties()
+	if len(duties) != 1 || duties[0].DutyID != "bob" {
+		t.Errorf("expected only bob in the rotation, got %+v", duties)
+	}
+	if duty, _ := repo.GetDutyByDutyID("alice"); duty != nil {
+		t.Errorf("expected inactive alice not to be found, got %+v", duty)
+	}
+	members, _ := repo.GetMembers()
+	if len(members) != 2 || members[0].DutyID != "alice" || members[0].Active {
+		t.Errorf("expected inactive alice among the members, got %+v", members)
+	}
+	if err := repo.ForTeam(2).SetActive(bob.ID, false); err == nil {
+		t.Error("expected an error for a record of another team")
+	}
+}
+
+func TestMemoryRepository_AddAndRemoveUnusualDays(t *testing.T) {
+	repo := NewMemoryRepository()
+	day := time.Date(2026, 5, 1, 15, 0, 0, 0, time.UTC)
+
+	if added, _ := repo.AddUnusualDay(UnusualDay{Date: day}); !added {
+		t.Error("expected the day to be added")
+	}
+	if added, _ := repo.AddUnusualDay(UnusualDay{Date: day.Add(time.Hour), Type: UnusualDayShortened, EndTime: "16:00"}); added {
+		t.Error("expected the same date not to be added twice")
+	}
+	if days, _ := repo.GetUnusualDays(time.Time{}); len(days) != 1 || days[0].Type != UnusualDayShortened || days[0].EndTime != "16:00" {
+		t.Errorf("expected the type and hours of the day to be replaced, got %+v", days)
+	}
+	if removed, _ := repo.RemoveUnusualDay(day); !removed {
+		t.Error("expected the day to be removed")
+	}
+	if removed, _ := repo.RemoveUnusualDay(day); removed {
+		t.Error("expected a missing day not to be removed")
+	}
+	if days, _ := repo.GetUnusualDays(time.Time{}); len(days) != 0 {
+		t.Errorf("expected no unusual days, got %v", days)
+	}
+}
+
+func TestMemoryRepository_ImportUnusualDayKeepsManualDays(t *testing.T) {
+	repo := NewMemoryRepository()
+	manual := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo.AddUnusualDay(UnusualDay{Date: manual, Type: UnusualDayWorking})
+
+	if outcome, _ := repo.ImportUnusualDay(UnusualDay{Date: manual, Type: UnusualDayHoliday, Source: "holidays.ics"}); outcome != ImportSkipped {
+		t.Errorf("expected the manual day to be skipped, got %s", outcome)
+	}
+	imported := UnusualDay{Date: manual.AddDate(0, 0, 1), Type: UnusualDayHoliday, Source: "holidays.ics"}
+	if outcome, _ := repo.ImportUnusualDay(imported); outcome != ImportAdded {
+		t.Errorf("expected a new day to be added, got %s", outcome)
+	}
+	if outcome, _ := repo.ImportUnusualDay(imported); outcome != ImportUpdated {
+		t.Errorf("expected an imported day to be updated, got %s", outcome)
+	}
+
+	days, _ := repo.GetUnusualDays(time.Time{})
+	if len(days) != 2 || days[0].Type != UnusualDayWorking || days[0].Source != "" || days[1].Source != "holidays.ics" {
+		t.Errorf("expected the manual day and one imported day, got %+v", days)
+	}
+}
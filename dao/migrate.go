@@ -0,0 +1,237 @@
+package dao
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migration is a versioned schema change embedded in the binary
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus describes whether a migration has been applied
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Migrations returns all embedded migrations ordered by version
+func Migrations() ([]Migration, error) {
+	return loadMigrations(migrationFiles, "migrations")
+}
+
+// loadMigrations reads <version>_<name>.up.sql / <version>_<name>.down.sql pairs from dir
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		fileName := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(fileName, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(fileName, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(fileName, "."+direction+".sql")
+		versionStr, name, found := strings.Cut(base, "_")
+		if !found {
+			return nil, fmt.Errorf("migration %s has no name", fileName)
+		}
+		version, err := strconv.ParseInt(versionStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s has invalid version: %w", fileName, err)
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, fileName))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", fileName, err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		}
+		if migration.Name != name {
+			return nil, fmt.Errorf("migration version %d is used by %s and %s", version, migration.Name, name)
+		}
+		if direction == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+func ensureMigrationsTable(db *sql.DB) error {
+	_, err := db.Exec(`create table if not exists schema_migrations
+(
+    version    bigint constraint schema_migrations_pk primary key,
+    name       text not null,
+    applied_at timestamptz not null default now()
+)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	return nil
+}
+
+func getAppliedMigrations(db *sql.DB) (map[int64]time.Time, error) {
+	rows, err := db.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			fmt.Printf("failed to close rows: %v", err)
+		}
+	}(rows)
+
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		applied[version] = appliedAt
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during row iteration: %w", err)
+	}
+
+	return applied, nil
+}
+
+// GetMigrationStatus lists every embedded migration together with the time it was applied
+func GetMigrationStatus(db *sql.DB) ([]MigrationStatus, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureMigrationsTable(db); err != nil {
+		return nil, err
+	}
+	applied, err := getAppliedMigrations(db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, len(migrations))
+	for i, migration := range migrations {
+		statuses[i] = MigrationStatus{Migration: migration}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			statuses[i].AppliedAt = &appliedAt
+		}
+	}
+	return statuses, nil
+}
+
+// MigrateUp applies all pending migrations in order and returns the ones it applied
+func MigrateUp(db *sql.DB) ([]Migration, error) {
+	statuses, err := GetMigrationStatus(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var appliedNow []Migration
+	for _, status := range statuses {
+		if status.AppliedAt != nil {
+			continue
+		}
+		err := runMigration(db, status.Migration.Up,
+			"INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", status.Version, status.Name)
+		if err != nil {
+			return appliedNow, fmt.Errorf("failed to apply migration %d_%s: %w", status.Version, status.Name, err)
+		}
+		appliedNow = append(appliedNow, status.Migration)
+	}
+	return appliedNow, nil
+}
+
+// MigrateDown rolls back the most recently applied migration.
+// It returns nil when there is nothing to roll back.
+func MigrateDown(db *sql.DB) (*Migration, error) {
+	statuses, err := GetMigrationStatus(db)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(statuses) - 1; i >= 0; i-- {
+		if statuses[i].AppliedAt == nil {
+			continue
+		}
+		migration := statuses[i].Migration
+		err := runMigration(db, migration.Down, "DELETE FROM schema_migrations WHERE version = $1", migration.Version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to roll back migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		return &migration, nil
+	}
+	return nil, nil
+}
+
+// runMigration executes the migration script and the bookkeeping statement in one transaction
+func runMigration(db *sql.DB, script string, bookkeeping string, args ...any) (err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			rollbackErr := tx.Rollback()
+			if rollbackErr != nil {
+				fmt.Printf("failed to rollback transaction: %v", rollbackErr)
+			}
+		}
+	}()
+
+	if _, err = tx.Exec(script); err != nil {
+		return fmt.Errorf("failed to execute migration script: %w", err)
+	}
+	if _, err = tx.Exec(bookkeeping, args...); err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package dao
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestMigrations_EmbeddedAreOrderedAndComplete(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("expected embedded migrations")
+	}
+	for i, migration := range migrations {
+		if migration.Up == "" || migration.Down == "" {
+			t.Errorf("migration %d_%s must have up and down scripts", migration.Version, migration.Name)
+		}
+		if i > 0 && migrations[i-1].Version >= migration.Version {
+			t.Errorf("migrations are not strictly ordered: %d before %d", migrations[i-1].Version, migration.Version)
+		}
+	}
+}
+
+func TestLoadMigrations_SortsByVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0002_second.up.sql":   {Data: []byte("select 2")},
+		"m/0002_second.down.sql": {Data: []byte("select -2")},
+		"m/0001_first.up.sql":    {Data: []byte("select 1")},
+		"m/0001_first.down.sql":  {Data: []byte("select -1")},
+		"m/README.md":            {Data: []byte("ignored")},
+	}
+
+	migrations, err := loadMigrations(fsys, "m")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(migrations) != 2 {
+		t.Fatalf("expected 2 migrations, got %d", len(migrations))
+	}
+	if migrations[0].Version != 1 || migrations[0].Name != "first" || migrations[0].Up != "select 1" {
+		t.Errorf("unexpected first migration: %+v", migrations[0])
+	}
+	if migrations[1].Version != 2 || migrations[1].Down != "select -2" {
+		t.Errorf("unexpected second migration: %+v", migrations[1])
+	}
+}
+
+func TestLoadMigrations_RejectsMissingDown(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0001_first.up.sql": {Data: []byte("select 1")},
+	}
+
+	if _, err := loadMigrations(fsys, "m"); err == nil {
+		t.Fatal("expected error for migration without down script")
+	}
+}
+
+func TestLoadMigrations_RejectsInvalidVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/first_migration.up.sql":   {Data: []byte("select 1")},
+		"m/first_migration.down.sql": {Data: []byte("select -1")},
+	}
+
+	if _, err := loadMigrations(fsys, "m"); err == nil {
+		t.Fatal("expected error for migration without numeric version")
+	}
+}
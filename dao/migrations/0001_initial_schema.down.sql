@@ -0,0 +1,2 @@
+drop table if exists duties;
+drop table if exists unusual_days;
@@ -0,0 +1,12 @@
+create table if not exists unusual_days
+(
+    id           bigserial constraint unusual_days_pk primary key,
+    unusual_date date not null
+);
+
+create table if not exists duties
+(
+    id             bigserial constraint duties_pk primary key,
+    duty_id        text not null,
+    last_duty_date date
+);
@@ -0,0 +1,12 @@
+create table duty_history
+(
+    id                      bigserial constraint duty_history_pk primary key,
+    duty_record_id          bigint      not null constraint duty_history_duties_fk references duties (id),
+    duty_date               date        not null,
+    replaced_duty_record_id bigint constraint duty_history_replaced_duties_fk references duties (id),
+    actor_user_id           text        not null default '',
+    reason                  text        not null,
+    created_at              timestamptz not null default now()
+);
+
+create index duty_history_duty_date_idx on duty_history (duty_date);
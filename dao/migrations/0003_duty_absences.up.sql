@@ -0,0 +1,11 @@
+create table duty_absences
+(
+    id             bigserial constraint duty_absences_pk primary key,
+    duty_record_id bigint not null constraint duty_absences_duties_fk references duties (id),
+    from_date      date   not null,
+    to_date        date   not null,
+    reason         text   not null default '',
+    constraint duty_absences_period_check check (from_date <= to_date)
+);
+
+create index duty_absences_to_date_idx on duty_absences (to_date);
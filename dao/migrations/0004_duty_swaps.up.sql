@@ -0,0 +1,14 @@
+create table duty_swaps
+(
+    id                       bigserial constraint duty_swaps_pk primary key,
+    swap_date                date        not null,
+    requester_duty_record_id bigint      not null constraint duty_swaps_requester_fk references duties (id),
+    target_duty_record_id    bigint      not null constraint duty_swaps_target_fk references duties (id),
+    status                   text        not null default 'pending',
+    created_at               timestamptz not null default now(),
+    resolved_at              timestamptz
+);
+
+create index duty_swaps_swap_date_idx on duty_swaps (swap_date);
+
+create unique index duty_swaps_accepted_uidx on duty_swaps (swap_date, requester_duty_record_id) where status = 'accepted';
@@ -0,0 +1,3 @@
+alter table duty_history drop column if exists role;
+
+alter table duties drop column if exists last_secondary_duty_date;
@@ -0,0 +1,3 @@
+alter table duties add column last_secondary_duty_date date;
+
+alter table duty_history add column role text not null default 'primary';
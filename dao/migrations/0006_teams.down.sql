@@ -0,0 +1,5 @@
+drop index if exists duties_team_id_idx;
+
+alter table duties drop column if exists team_id;
+
+drop table if exists teams;
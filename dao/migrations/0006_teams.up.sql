@@ -0,0 +1,18 @@
+create table teams
+(
+    id                    bigserial constraint teams_pk primary key,
+    name                  text not null constraint teams_name_uk unique,
+    main_chat_id          text not null default '',
+    support_chat_id       text not null default '',
+    next_allowed_user_ids text not null default '',
+    start_time            text not null default '',
+    end_time              text not null default '',
+    days_off              text not null default ''
+);
+
+insert into teams (id, name) values (1, 'default');
+select setval(pg_get_serial_sequence('teams', 'id'), 1);
+
+alter table duties add column team_id bigint not null default 1 constraint duties_teams_fk references teams (id);
+
+create index duties_team_id_idx on duties (team_id);
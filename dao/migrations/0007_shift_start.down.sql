@@ -0,0 +1,4 @@
+alter table duties drop column if exists secondary_shift_start_date;
+alter table duties drop column if exists shift_start_date;
+
+alter table teams drop column if exists rotation_period;
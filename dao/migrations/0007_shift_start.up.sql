@@ -0,0 +1,6 @@
+alter table teams add column rotation_period text not null default '';
+
+alter table duties add column shift_start_date date;
+alter table duties add column secondary_shift_start_date date;
+
+update duties set shift_start_date = last_duty_date, secondary_shift_start_date = last_secondary_duty_date;
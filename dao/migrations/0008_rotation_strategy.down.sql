@@ -0,0 +1,4 @@
+alter table teams drop column if exists rotation_strategy;
+
+alter table duties drop column if exists weight;
+alter table duties drop column if exists position;
@@ -0,0 +1,4 @@
+alter table duties add column position integer not null default 0;
+alter table duties add column weight double precision not null default 1 constraint duties_weight_check check (weight > 0);
+
+alter table teams add column rotation_strategy text not null default '';
@@ -0,0 +1 @@
+drop table if exists duty_pages;
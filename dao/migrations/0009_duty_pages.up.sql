@@ -0,0 +1,10 @@
+create table duty_pages
+(
+    id             bigserial constraint duty_pages_pk primary key,
+    duty_record_id bigint      not null constraint duty_pages_duties_fk references duties (id),
+    paged_at       timestamptz not null default now(),
+    actor_user_id  text        not null default '',
+    role           text        not null default 'primary'
+);
+
+create index duty_pages_paged_at_idx on duty_pages (paged_at);
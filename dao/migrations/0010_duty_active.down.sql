@@ -0,0 +1 @@
+alter table duties drop column if exists active;
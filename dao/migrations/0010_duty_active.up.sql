@@ -0,0 +1 @@
+alter table duties add column active boolean not null default true;
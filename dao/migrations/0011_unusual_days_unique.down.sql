@@ -0,0 +1 @@
+alter table unusual_days drop constraint if exists unusual_days_date_uk;
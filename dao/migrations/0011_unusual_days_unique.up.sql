@@ -0,0 +1,3 @@
+delete from unusual_days a using unusual_days b where a.unusual_date = b.unusual_date and a.id > b.id;
+
+alter table unusual_days add constraint unusual_days_date_uk unique (unusual_date);
@@ -0,0 +1,3 @@
+alter table unusual_days drop column if exists end_time;
+alter table unusual_days drop column if exists start_time;
+alter table unusual_days drop column if exists day_type;
@@ -0,0 +1,4 @@
+alter table unusual_days add column day_type text not null default 'inverted'
+    constraint unusual_days_type_check check (day_type in ('inverted', 'holiday', 'working', 'shortened'));
+alter table unusual_days add column start_time text not null default '';
+alter table unusual_days add column end_time text not null default '';
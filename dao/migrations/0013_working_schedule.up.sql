@@ -0,0 +1 @@
+alter table teams add column working_schedule text not null default '';
@@ -0,0 +1 @@
+alter table teams drop column if exists timezone;
@@ -0,0 +1 @@
+alter table teams add column timezone text not null default '';
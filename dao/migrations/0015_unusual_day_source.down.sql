@@ -0,0 +1 @@
+alter table unusual_days drop column if exists source;
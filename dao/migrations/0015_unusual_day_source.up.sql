@@ -0,0 +1 @@
+alter table unusual_days add column source text not null default '';
@@ -0,0 +1,201 @@
+package dao
+
+import "time"
+
+// Reasons recorded in the duty history
+const (
+	ReasonRotation = "rotation"
+	ReasonNext     = "next"
+	ReasonSwap     = "swap"
+)
+
+// Duty roles, every day has a primary duty person and a secondary one as a backup
+const (
+	RolePrimary   = "primary"
+	RoleSecondary = "secondary"
+)
+
+// Swap statuses
+const (
+	SwapPending  = "pending"
+	SwapAccepted = "accepted"
+	SwapDeclined = "declined"
+)
+
+// Assignment describes who is put on duty for a date, by whom and why
+type Assignment struct {
+	DutyRecordID int64 // record whose last_duty_date advances the rotation
+	// SubstituteRecordID is the record actually on duty when it differs from DutyRecordID, e.g. after a swap.
+	// The history then lists the substitute as on duty and DutyRecordID as replaced.
+	SubstituteRecordID int64
+	Date               time.Time
+	ActorUserID        string
+	Reason             string
+	// Role selects the rotation, an empty role is the primary one
+	Role string
+	// ShiftStart is the first day of the shift the assignment continues, a zero value starts a new shift on Date
+	ShiftStart time.Time
+}
+
+// Swap is a request to hand over the duty of a date from the requester to the target
+type Swap struct {
+	ID                int64
+	Date              time.Time
+	RequesterRecordID int64
+	RequesterDutyID   string
+	TargetRecordID    int64
+	TargetDutyID      string
+	Status            string
+	CreatedAt         time.Time
+}
+
+// HistoryEntry is a single row of the append-only duty history
+type HistoryEntry struct {
+	ID             int64
+	DutyRecordID   int64
+	DutyID         string
+	DutyDate       time.Time
+	ReplacedDutyID string // duty_id of the person who was replaced, empty if nobody was
+	ActorUserID    string
+	Reason         string
+	Role           string
+	CreatedAt      time.Time
+}
+
+// Page is a call of a duty person, by a user or by another system
+type Page struct {
+	ID           int64
+	DutyRecordID int64
+	DutyID       string
+	PagedAt      time.Time
+	ActorUserID  string
+	Role         string
+}
+
+// Absence is a period when a duty person is unavailable, both ends inclusive
+type Absence struct {
+	ID           int64
+	DutyRecordID int64
+	From         time.Time
+	To           time.Time
+	Reason       string
+}
+
+// Covers reports whether the absence includes the given date
+func (a Absence) Covers(date time.Time) bool {
+	day := truncateToDate(date)
+	return !day.Before(truncateToDate(a.From)) && !day.After(truncateToDate(a.To))
+}
+
+// DutyRepository provides access to the duty rotation records
+type DutyRepository interface {
+	// GetAllDuties returns the active duty records, which make up the rotation
+	GetAllDuties() ([]Duty, error)
+	// GetDutyByDutyID returns the active duty record for the given duty_id or nil if there is none
+	GetDutyByDutyID(dutyID string) (*Duty, error)
+	// GetMembers returns all duty records including inactive ones
+	GetMembers() ([]Duty, error)
+	// AddMember inserts an active duty record for dutyID
+	AddMember(dutyID string) (Duty, error)
+	// SetActive moves a duty record in or out of the rotation, inactive records keep their history
+	SetActive(dutyRecordID int64, active bool) error
+	// UpdateDutyDate sets the last duty date of the assignment role and appends the assignment to the history
+	UpdateDutyDate(assignment Assignment) error
+	// ReassignDutyDate moves the date of the assignment role from its current holder and appends the assignment to the history
+	ReassignDutyDate(assignment Assignment) error
+	// GetDutyHistory returns history entries with duty_date >= since, most recent first
+	GetDutyHistory(since time.Time) ([]HistoryEntry, error)
+	// GetAbsences returns absences overlapping the period between from and to inclusive
+	GetAbsences(from, to time.Time) ([]Absence, error)
+	AddAbsence(absence Absence) (Absence, error)
+	// EndAbsences cancels the absences of a duty record from date onwards and returns how many were changed
+	EndAbsences(dutyRecordID int64, date time.Time) (int64, error)
+	CreateSwap(swap Swap) (Swap, error)
+	// GetPendingSwaps returns pending swaps addressed to the target record, newest first
+	GetPendingSwaps(targetRecordID int64) ([]Swap, error)
+	// GetAcceptedSwaps returns accepted swaps with dates between from and to inclusive
+	GetAcceptedSwaps(from, to time.Time) ([]Swap, error)
+	// ResolveSwap moves a pending swap to the given status
+	ResolveSwap(swapID int64, status string) error
+	// RecordPage stores a call of a duty person
+	RecordPage(page Page) error
+	// GetPages returns pages made since the given time, oldest first
+	GetPages(since time.Time) ([]Page, error)
+}
+
+// roleOf returns the role of the assignment, defaulting to the primary one
+func roleOf(assignment Assignment) string {
+	if assignment.Role == "" {
+		return RolePrimary
+	}
+	return assignment.Role
+}
+
+// shiftStartOf returns the first day of the shift the assignment belongs to
+func shiftStartOf(assignment Assignment) time.Time {
+	if assignment.ShiftStart.IsZero() {
+		return assignment.Date
+	}
+	return assignment.ShiftStart
+}
+
+func truncateToDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Types of unusual days
+const (
+	// UnusualDayInverted turns a working day into a day off and a day off into a whole working day.
+	// Rows created before unusual days had a type have it.
+	UnusualDayInverted = "inverted"
+	// UnusualDayHoliday is a day off
+	UnusualDayHoliday = "holiday"
+	// UnusualDayWorking is a working day, with the usual hours unless the day has its own
+	UnusualDayWorking = "working"
+	// UnusualDayShortened is a working day that ends an hour early unless the day has its own hours
+	UnusualDayShortened = "shortened"
+)
+
+// UnusualDay is a date on which the working calendar differs from the usual week
+type UnusualDay struct {
+	Date time.Time
+	// Type is one of the UnusualDay* types, empty is UnusualDayInverted
+	Type string
+	// StartTime and EndTime are optional working hours of the day in "HH:MM" format
+	StartTime string
+	EndTime   string
+	// Source is the calendar file or URL the day was imported from, empty for a day entered by hand
+	Source string
+}
+
+// TypeOrDefault returns the type of the day, defaulting to UnusualDayInverted
+func (d UnusualDay) TypeOrDefault() string {
+	if d.Type == "" {
+		return UnusualDayInverted
+	}
+	return d.Type
+}
+
+// CalendarRepository provides access to the unusual days of the working calendar
+type CalendarRepository interface {
+	// GetUnusualDays returns the unusual days on or after currentDate in chronological order
+	GetUnusualDays(currentDate time.Time) ([]UnusualDay, error)
+	// AddUnusualDay stores the unusual day, replacing the type and hours of an existing one on the same date.
+	// It reports false when the date already was an unusual day.
+	AddUnusualDay(day UnusualDay) (bool, error)
+	// RemoveUnusualDay deletes the unusual day, it reports false when the date was not one
+	RemoveUnusualDay(day time.Time) (bool, error)
+	// ImportUnusualDay stores an unusual day taken from an imported calendar and returns one of the
+	// Import* outcomes. A day entered by hand on the same date is kept as it is.
+	ImportUnusualDay(day UnusualDay) (string, error)
+}
+
+// Outcomes of ImportUnusualDay
+const (
+	// ImportAdded means the date was not an unusual day before
+	ImportAdded = "added"
+	// ImportUpdated means the date was already imported and got the type, hours and source of the new day
+	ImportUpdated = "updated"
+	// ImportSkipped means the date was entered by hand and was left untouched
+	ImportSkipped = "skipped"
+)
@@ -0,0 +1,73 @@
+package dao
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// DefaultTeamID is the team created by the migrations, it owns all duties of a single-team deployment
+const DefaultTeamID int64 = 1
+
+// Team is an independent rotation with its own members, chats, working calendar and allowed users.
+// Empty settings of the default team fall back to the environment variables.
+type Team struct {
+	ID                 int64
+	Name               string
+	MainChatId         string
+	SupportChatId      string
+	NextAllowedUserIds string // semicolon-separated, like NEXT_ALLOWED_USER_IDS
+	StartTime          string // "HH:MM", like START_TIME
+	EndTime            string // "HH:MM", like END_TIME
+	DaysOff            string // comma-separated weekdays, like DAYS_OFF
+	WorkingSchedule    string // working windows of every weekday, like WORKING_SCHEDULE
+	Timezone           string // IANA time zone of the working calendar and the duty days, like CALENDAR_TIMEZONE
+	RotationPeriod     string // shift length, like ROTATION_PERIOD
+	RotationStrategy   string // order of the rotation, like ROTATION_STRATEGY
+}
+
+// TeamRepository provides access to the teams
+type TeamRepository interface {
+	// GetTeams returns all teams ordered by ID
+	GetTeams() ([]Team, error)
+}
+
+// GetTeams retrieves all teams ordered by ID
+func (r *PostgresRepository) GetTeams() ([]Team, error) {
+	rows, err := r.db.Query(`SELECT id, name, main_chat_id, support_chat_id, next_allowed_user_ids, start_time, end_time, days_off,
+       working_schedule, timezone, rotation_period, rotation_strategy
+FROM teams
+ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			fmt.Printf("failed to close rows: %v", err)
+		}
+	}(rows)
+
+	var teams []Team
+	for rows.Next() {
+		var team Team
+		if err := rows.Scan(&team.ID, &team.Name, &team.MainChatId, &team.SupportChatId, &team.NextAllowedUserIds,
+			&team.StartTime, &team.EndTime, &team.DaysOff, &team.WorkingSchedule, &team.Timezone, &team.RotationPeriod, &team.RotationStrategy); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		teams = append(teams, team)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error occurred during row iteration: %w", err)
+	}
+
+	return teams, nil
+}
+
+// ForTeam returns a repository that shares the connection pool and sees only the duties of teamID
+func (r *PostgresRepository) ForTeam(teamID int64) *PostgresRepository {
+	return &PostgresRepository{
+		db:     r.db,
+		teamID: teamID,
+	}
+}
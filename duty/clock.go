@@ -0,0 +1,29 @@
+package duty
+
+import "time"
+
+// Clock tells the current time to the service, tests replace it to control the duty day
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to Clock
+type ClockFunc func() time.Time
+
+// Now returns the result of the function
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the time of the operating system
+var SystemClock Clock = ClockFunc(time.Now)
+
+// DateIn returns the calendar date of t in the location as midnight UTC, like the dates read from the database.
+// A nil location uses the local time zone.
+func DateIn(t time.Time, location *time.Location) time.Time {
+	if location == nil {
+		location = time.Local
+	}
+	t = t.In(location)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,169 @@
+package duty
+
+import (
+	"testing"
+	"time"
+
+	"watch_bot/dao"
+)
+
+// fixedClock is a Clock that tests move by hand
+type fixedClock struct {
+	now time.Time
+}
+
+func (c *fixedClock) Now() time.Time {
+	return c.now
+}
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("failed to load %s: %v", name, err)
+	}
+	return location
+}
+
+func TestDateIn(t *testing.T) {
+	tests := []struct {
+		name     string
+		location string
+		now      time.Time
+		want     time.Time
+	}{
+		{"moscow after midnight", "Europe/Moscow", time.Date(2026, 3, 10, 21, 30, 0, 0, time.UTC), time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)},
+		{"moscow before midnight", "Europe/Moscow", time.Date(2026, 3, 10, 20, 59, 0, 0, time.UTC), time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"new york before midnight", "America/New_York", time.Date(2026, 3, 11, 3, 30, 0, 0, time.UTC), time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)},
+		{"new york after midnight", "America/New_York", time.Date(2026, 3, 11, 4, 0, 0, 0, time.UTC), time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)},
+		{"new york before the clocks go forward", "America/New_York", time.Date(2026, 3, 8, 4, 59, 0, 0, time.UTC), time.Date(2026, 3, 7, 0, 0, 0, 0, time.UTC)},
+		{"kiritimati is a day ahead", "Pacific/Kiritimati", time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC), time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)},
+		{"utc", "UTC", time.Date(2026, 3, 10, 23, 59, 59, 0, time.UTC), time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DateIn(tt.now, mustLoadLocation(t, tt.location)); !got.Equal(tt.want) || got.Location() != time.UTC {
+				t.Errorf("DateIn() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_GetCurrentDuty_LocalMidnight(t *testing.T) {
+	lastDutyDate := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		location string
+		now      time.Time
+		want     string
+		isNew    bool
+	}{
+		// 01:30 in Moscow is still the previous day in UTC
+		{"moscow after midnight", "Europe/Moscow", time.Date(2026, 3, 10, 22, 30, 0, 0, time.UTC), "bob", true},
+		{"utc before midnight", "UTC", time.Date(2026, 3, 10, 22, 30, 0, 0, time.UTC), "alice", false},
+		// 23:30 in New York is already the next day in UTC
+		{"new york before midnight", "America/New_York", time.Date(2026, 3, 11, 3, 30, 0, 0, time.UTC), "alice", false},
+		{"utc after midnight", "UTC", time.Date(2026, 3, 11, 3, 30, 0, 0, time.UTC), "bob", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := dao.NewMemoryRepository()
+			repo.AddDuty("alice", &lastDutyDate)
+			repo.AddDuty("bob", nil)
+			service := NewService(repo).WithLocation(mustLoadLocation(t, tt.location)).WithClock(&fixedClock{now: tt.now})
+
+			result, err := service.GetCurrentDuty("caller")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result == nil || result.DutyID != tt.want || result.IsNewAssignment != tt.isNew {
+				t.Errorf("expected %s (new assignment %v), got %+v", tt.want, tt.isNew, result)
+			}
+		})
+	}
+}
+
+func TestService_RotationAcrossMidnight(t *testing.T) {
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+	repo := dao.NewMemoryRepository()
+	repo.AddDuty("alice", nil)
+	repo.AddDuty("bob", nil)
+	repo.AddDuty("charlie", nil)
+	clock := &fixedClock{now: time.Date(2026, 3, 10, 23, 58, 0, 0, tokyo)}
+	service := NewService(repo).WithLocation(tokyo).WithClock(clock)
+
+	expect := func(want string, isNew bool) {
+		t.Helper()
+		result, err := service.GetCurrentDuty("caller")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result == nil || result.DutyID != want || result.IsNewAssignment != isNew {
+			t.Fatalf("at %v expected %s (new assignment %v), got %+v", clock.now, want, isNew, result)
+		}
+	}
+
+	expect("alice", true)
+	clock.now = time.Date(2026, 3, 10, 23, 59, 59, 0, tokyo)
+	expect("alice", false)
+	clock.now = time.Date(2026, 3, 11, 0, 0, 0, 0, tokyo)
+	expect("bob", true)
+
+	// \next shortly after midnight reassigns the new day, not the previous one
+	clock.now = time.Date(2026, 3, 11, 0, 5, 0, 0, tokyo)
+	next, err := service.GetNextDuty("caller")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next == nil || next.DutyID != "charlie" {
+		t.Fatalf("expected charlie to be next, got %+v", next)
+	}
+	history, err := service.GetHistory(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, entry := range history {
+		if !isSameDay(entry.DutyDate, time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("expected only entries of March 11, got %+v", entry)
+		}
+	}
+	if len(history) == 0 {
+		t.Error("expected the reassignment in today's history")
+	}
+}
+
+func TestService_WeeklyHandoverAtLocalMidnight(t *testing.T) {
+	moscow := mustLoadLocation(t, "Europe/Moscow")
+	sunday := time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)
+	shiftStart := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	repo := dao.NewMemoryRepository()
+	alice := repo.AddDuty("alice", &sunday)
+	repo.AddDuty("bob", nil)
+	if err := repo.UpdateDutyDate(dao.Assignment{DutyRecordID: alice.ID, SubstituteRecordID: alice.ID, Date: sunday, ShiftStart: shiftStart}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rotation := Rotation{Period: PeriodWeekly, HandoverDay: time.Monday}
+
+	// Sunday 23:30 in UTC is already Monday 02:30 in Moscow
+	sundayNight := time.Date(2026, 3, 8, 23, 30, 0, 0, time.UTC)
+	inUTC := NewService(repo).WithRotation(rotation).WithLocation(time.UTC).WithClock(&fixedClock{now: sundayNight})
+	result, err := inUTC.GetCurrentDuty("caller")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result == nil || result.DutyID != "alice" {
+		t.Fatalf("expected alice to keep the shift until Monday in UTC, got %+v", result)
+	}
+
+	inMoscow := inUTC.WithLocation(moscow)
+	result, err = inMoscow.GetCurrentDuty("caller")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result == nil || result.DutyID != "bob" || !result.IsNewAssignment {
+		t.Fatalf("expected bob to start the Monday shift in Moscow, got %+v", result)
+	}
+}
@@ -1,94 +1,447 @@
 package duty
 
 import (
+	"errors"
+	"fmt"
+	"slices"
 	"sort"
 	"time"
 
 	"watch_bot/dao"
 )
 
+var (
+	// ErrNotInRotation is returned when a user has no duty record
+	ErrNotInRotation = errors.New("user is not in the duty rotation")
+	// ErrAlreadyMember is returned when an active member is added to the rotation again
+	ErrAlreadyMember = errors.New("user is already in the duty rotation")
+	// ErrSwapTargetNotInRotation is returned when the other side of a swap has no duty record
+	ErrSwapTargetNotInRotation = errors.New("swap target is not in the duty rotation")
+	// ErrSwapWithSelf is returned when a user tries to swap with themselves
+	ErrSwapWithSelf = errors.New("cannot swap duty with yourself")
+	// ErrSwapInPast is returned for swaps of dates before today
+	ErrSwapInPast = errors.New("cannot swap a past date")
+	// ErrNoPendingSwap is returned when there is no pending swap to accept or decline
+	ErrNoPendingSwap = errors.New("no pending swap")
+	// ErrSwapNotOnDuty is returned when the rotation does not put the requester on duty on the date of a swap
+	ErrSwapNotOnDuty = errors.New("requester is not on duty on the swap date")
+	// ErrSwapTargetAbsent is returned when the other side of a swap is absent on its date
+	ErrSwapTargetAbsent = errors.New("swap target is absent on the swap date")
+	// ErrSwapTooFar is returned for swaps of dates beyond the reach of the schedule projection
+	ErrSwapTooFar = errors.New("cannot swap a date that far ahead")
+)
+
 // Service handles duty-related business logic
 type Service struct {
-	connectionStr string
+	repository dao.DutyRepository
+	rotation   Rotation
+	// location sets the day boundaries, nil uses the local time zone
+	location *time.Location
+	clock    Clock
 }
 
 // NewService creates a new duty service
-func NewService(connectionStr string) *Service {
+func NewService(repository dao.DutyRepository) *Service {
 	return &Service{
-		connectionStr: connectionStr,
+		repository: repository,
+		clock:      SystemClock,
 	}
 }
 
+// WithRotation returns a service that shares the repository and hands the duty over as the rotation says
+func (s *Service) WithRotation(rotation Rotation) *Service {
+	service := *s
+	service.rotation = rotation
+	return &service
+}
+
+// WithLocation returns a service that starts the days at midnight in the location
+func (s *Service) WithLocation(location *time.Location) *Service {
+	service := *s
+	service.location = location
+	return &service
+}
+
+// WithClock returns a service that reads the current time from the clock
+func (s *Service) WithClock(clock Clock) *Service {
+	service := *s
+	service.clock = clock
+	return &service
+}
+
 // DutyResult contains information about the current duty person
 type DutyResult struct {
 	DutyID          string // ID of the duty chat
 	IsNewAssignment bool   // True if duty was just assigned today (first call of the day)
+	SecondaryDutyID string // ID of the backup duty chat, empty if nobody else is available
 }
 
 // GetCurrentDuty returns the current duty person and updates the database if needed
 // Algorithm:
 // 1. Find record where last_duty_date = today -> return it
-// 2. If not found, find record with max last_duty_date and get next by duty_id alphabetically
-// 3. Update the found record with today's date
-func (s *Service) GetCurrentDuty() (*DutyResult, error) {
-	duties, err := dao.GetAllDuties(s.connectionStr)
+// 2. If not found, keep the record with max last_duty_date while its shift runs, otherwise get next in the order of the rotation strategy
+// 3. Update the found record with today's date and record the assignment in the history
+// An accepted swap for today hands the duty to the swap target while the rotation keeps its position.
+func (s *Service) GetCurrentDuty(actorUserId string) (*DutyResult, error) {
+	duties, err := s.repository.GetAllDuties()
 	if err != nil {
 		return nil, err
 	}
 
-	currentDate := time.Now().Truncate(24 * time.Hour)
-	duty := FindCurrentDuty(duties, currentDate)
+	currentDate := s.today()
+	absences, err := s.repository.GetAbsences(currentDate, currentDate)
+	if err != nil {
+		return nil, err
+	}
+	duty, shiftStart := FindShiftDuty(duties, currentDate, absences, s.rotation)
 	if duty == nil {
 		return nil, nil
 	}
 
+	swaps, err := s.repository.GetAcceptedSwaps(currentDate, currentDate)
+	if err != nil {
+		return nil, err
+	}
+	onDuty := ApplySwap(duties, duty, currentDate, swaps)
+
 	// Check if this is a new assignment (duty was not yet assigned today)
 	isNewAssignment := duty.LastDutyDate == nil || !isSameDay(*duty.LastDutyDate, currentDate)
 
 	// Check if we need to update the database
 	if isNewAssignment {
-		err = dao.UpdateDutyDate(s.connectionStr, duty.ID, currentDate)
+		reason := dao.ReasonRotation
+		if onDuty != duty {
+			reason = dao.ReasonSwap
+		}
+		err = s.repository.UpdateDutyDate(dao.Assignment{
+			DutyRecordID:       duty.ID,
+			SubstituteRecordID: onDuty.ID,
+			Date:               currentDate,
+			ActorUserID:        actorUserId,
+			Reason:             reason,
+			ShiftStart:         shiftStart,
+		})
 		if err != nil {
 			return nil, err
 		}
 		duty.LastDutyDate = &currentDate
 	}
 
+	secondary, err := s.assignSecondary(duties, onDuty, currentDate, absences, actorUserId)
+	if err != nil {
+		return nil, err
+	}
+
 	return &DutyResult{
-		DutyID:          duty.DutyID,
+		DutyID:          onDuty.DutyID,
 		IsNewAssignment: isNewAssignment,
+		SecondaryDutyID: secondary,
 	}, nil
 }
 
-// GetNextDuty forcefully moves today's duty to the next person in alphabetical rotation.
-func (s *Service) GetNextDuty() (*DutyResult, error) {
-	duties, err := dao.GetAllDuties(s.connectionStr)
+// PeekCurrentDuty returns who is on duty today, or would be as the rotation stands, without assigning anybody.
+// It serves calls on days that must not enter the rotation, such as days off. The result is never a new assignment.
+func (s *Service) PeekCurrentDuty() (*DutyResult, error) {
+	duties, err := s.repository.GetAllDuties()
 	if err != nil {
 		return nil, err
 	}
 
-	currentDate := time.Now().Truncate(24 * time.Hour)
-	duty := FindNextDuty(duties, currentDate)
+	currentDate := s.today()
+	absences, err := s.repository.GetAbsences(currentDate, currentDate)
+	if err != nil {
+		return nil, err
+	}
+	duty, _ := FindShiftDuty(duties, currentDate, absences, s.rotation)
 	if duty == nil {
 		return nil, nil
 	}
+	swaps, err := s.repository.GetAcceptedSwaps(currentDate, currentDate)
+	if err != nil {
+		return nil, err
+	}
+	return &DutyResult{DutyID: ApplySwap(duties, duty, currentDate, swaps).DutyID}, nil
+}
+
+// GetNextDuty forcefully moves today's duty to the next person in rotation.
+func (s *Service) GetNextDuty(actorUserId string) (*DutyResult, error) {
+	duties, err := s.repository.GetAllDuties()
+	if err != nil {
+		return nil, err
+	}
+
+	currentDate := s.today()
+	absences, err := s.repository.GetAbsences(currentDate, currentDate)
+	if err != nil {
+		return nil, err
+	}
+	duty := FindNextDuty(duties, currentDate, absences, s.rotation)
+	if duty == nil {
+		return nil, nil
+	}
+
+	swaps, err := s.repository.GetAcceptedSwaps(currentDate, currentDate)
+	if err != nil {
+		return nil, err
+	}
+	onDuty := ApplySwap(duties, duty, currentDate, swaps)
+
+	err = s.repository.ReassignDutyDate(dao.Assignment{
+		DutyRecordID:       duty.ID,
+		SubstituteRecordID: onDuty.ID,
+		Date:               currentDate,
+		ActorUserID:        actorUserId,
+		Reason:             dao.ReasonNext,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	err = dao.ReassignDutyDate(s.connectionStr, duty.ID, currentDate)
+	secondary, err := s.assignSecondary(duties, onDuty, currentDate, absences, actorUserId)
 	if err != nil {
 		return nil, err
 	}
 
 	return &DutyResult{
-		DutyID:          duty.DutyID,
+		DutyID:          onDuty.DutyID,
 		IsNewAssignment: true,
+		SecondaryDutyID: secondary,
 	}, nil
 }
 
-// FindCurrentDuty finds the current duty person from a list of duties
+// assignSecondary selects today's secondary duty person from the secondary rotation, records the
+// assignment when it is new and returns their duty ID, or an empty string when nobody is available
+func (s *Service) assignSecondary(duties []dao.Duty, primary *dao.Duty, currentDate time.Time, absences []dao.Absence, actorUserId string) (string, error) {
+	secondary, shiftStart := FindSecondaryDuty(duties, primary, currentDate, absences, s.rotation)
+	if secondary == nil {
+		return "", nil
+	}
+	if secondary.LastSecondaryDutyDate != nil && isSameDay(*secondary.LastSecondaryDutyDate, currentDate) {
+		return secondary.DutyID, nil
+	}
+
+	assignment := dao.Assignment{
+		DutyRecordID: secondary.ID,
+		Date:         currentDate,
+		ActorUserID:  actorUserId,
+		Reason:       dao.ReasonRotation,
+		Role:         dao.RoleSecondary,
+		ShiftStart:   shiftStart,
+	}
+	// The primary may hold today's secondary date after a \next or a swap, hand it over then
+	if primary.LastSecondaryDutyDate != nil && isSameDay(*primary.LastSecondaryDutyDate, currentDate) {
+		err := s.repository.ReassignDutyDate(assignment)
+		if err != nil {
+			return "", err
+		}
+		return secondary.DutyID, nil
+	}
+	if err := s.repository.UpdateDutyDate(assignment); err != nil {
+		return "", err
+	}
+	secondary.LastSecondaryDutyDate = &currentDate
+	return secondary.DutyID, nil
+}
+
+// GetEscalationCandidate returns the next person in rotation after afterDutyID who is available today
+// and not in excluded, or an empty string when nobody is left to escalate to
+func (s *Service) GetEscalationCandidate(afterDutyID string, excluded []string) (string, error) {
+	duties, err := s.repository.GetAllDuties()
+	if err != nil {
+		return "", err
+	}
+
+	currentDate := s.today()
+	absences, err := s.repository.GetAbsences(currentDate, currentDate)
+	if err != nil {
+		return "", err
+	}
+	candidate := FindEscalationCandidate(duties, afterDutyID, currentDate, absences, excluded, s.rotation)
+	if candidate == nil {
+		return "", nil
+	}
+	return candidate.DutyID, nil
+}
+
+// GetHistory returns the duty history for the last days days including today, most recent first
+func (s *Service) GetHistory(days int) ([]dao.HistoryEntry, error) {
+	since := s.today().AddDate(0, 0, -(days - 1))
+	return s.repository.GetDutyHistory(since)
+}
+
+// AddAbsence marks the user as unavailable between from and to inclusive
+func (s *Service) AddAbsence(userId string, from, to time.Time, reason string) (*dao.Absence, error) {
+	duty, err := s.repository.GetDutyByDutyID(userId)
+	if err != nil {
+		return nil, err
+	}
+	if duty == nil {
+		return nil, ErrNotInRotation
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("absence ends before it starts")
+	}
+
+	absence, err := s.repository.AddAbsence(dao.Absence{
+		DutyRecordID: duty.ID,
+		From:         from,
+		To:           to,
+		Reason:       reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &absence, nil
+}
+
+// EndAbsence makes the user available again from today and returns the number of absences changed
+func (s *Service) EndAbsence(userId string) (int64, error) {
+	duty, err := s.repository.GetDutyByDutyID(userId)
+	if err != nil {
+		return 0, err
+	}
+	if duty == nil {
+		return 0, ErrNotInRotation
+	}
+	return s.repository.EndAbsences(duty.ID, s.today())
+}
+
+// RequestSwap creates a pending request for targetUserId to take the duty of requesterUserId on date.
+// The rotation must put the requester on duty on that working day, isWorkingDay tells the working days
+// the rotation moves on, nil treats every day as a working day. The target must not be absent then.
+func (s *Service) RequestSwap(requesterUserId string, date time.Time, targetUserId string, isWorkingDay func(time.Time) bool) (*dao.Swap, error) {
+	if requesterUserId == targetUserId {
+		return nil, ErrSwapWithSelf
+	}
+	today := s.today()
+	if date.Before(today) {
+		return nil, ErrSwapInPast
+	}
+	if date.After(today.AddDate(0, 0, maxScheduleLookahead)) {
+		return nil, ErrSwapTooFar
+	}
+
+	requester, err := s.repository.GetDutyByDutyID(requesterUserId)
+	if err != nil {
+		return nil, err
+	}
+	if requester == nil {
+		return nil, ErrNotInRotation
+	}
+	target, err := s.repository.GetDutyByDutyID(targetUserId)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, ErrSwapTargetNotInRotation
+	}
+	if err := s.checkSwap(requester, target, date, isWorkingDay); err != nil {
+		return nil, err
+	}
+
+	swap, err := s.repository.CreateSwap(dao.Swap{
+		Date:              date,
+		RequesterRecordID: requester.ID,
+		RequesterDutyID:   requester.DutyID,
+		TargetRecordID:    target.ID,
+		TargetDutyID:      target.DutyID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &swap, nil
+}
+
+// checkSwap makes sure that the swap takes effect: ApplySwap hands over only the days on which the rotation
+// picks the requester, and the target must be able to take the day
+func (s *Service) checkSwap(requester, target *dao.Duty, date time.Time, isWorkingDay func(time.Time) bool) error {
+	today := s.today()
+	// there are at most as many working days as calendar days up to the date
+	dates := NextWorkingDays(today, int(date.Sub(today).Hours()/24)+1, isWorkingDay)
+	end := slices.IndexFunc(dates, func(d time.Time) bool { return isSameDay(d, date) })
+	if end == -1 {
+		return ErrSwapNotOnDuty
+	}
+	dates = dates[:end+1]
+	duties, err := s.repository.GetAllDuties()
+	if err != nil {
+		return err
+	}
+	absences, err := s.repository.GetAbsences(today, date)
+	if err != nil {
+		return err
+	}
+
+	// accepted swaps move nobody in the rotation, so they are left out of the projection
+	schedule := ProjectSchedule(duties, dates, absences, nil, s.rotation)
+	if schedule[len(schedule)-1].DutyID != requester.DutyID {
+		return ErrSwapNotOnDuty
+	}
+	for _, absence := range absences {
+		if absence.DutyRecordID == target.ID && absence.Covers(date) {
+			return ErrSwapTargetAbsent
+		}
+	}
+	return nil
+}
+
+// ResolveSwap accepts or declines a pending swap addressed to targetUserId.
+// When swapID is 0 the most recent pending swap is resolved.
+func (s *Service) ResolveSwap(targetUserId string, swapID int64, accept bool) (*dao.Swap, error) {
+	target, err := s.repository.GetDutyByDutyID(targetUserId)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, ErrNotInRotation
+	}
+
+	pending, err := s.repository.GetPendingSwaps(target.ID)
+	if err != nil {
+		return nil, err
+	}
+	var swap *dao.Swap
+	for i := range pending {
+		if swapID == 0 || pending[i].ID == swapID {
+			swap = &pending[i]
+			break
+		}
+	}
+	if swap == nil {
+		return nil, ErrNoPendingSwap
+	}
+
+	status := dao.SwapDeclined
+	if accept {
+		status = dao.SwapAccepted
+	}
+	if err := s.repository.ResolveSwap(swap.ID, status); err != nil {
+		return nil, err
+	}
+	swap.Status = status
+	return swap, nil
+}
+
+// today returns the current date in the time zone of the service
+func (s *Service) today() time.Time {
+	return DateIn(s.clock.Now(), s.location)
+}
+
+// FindCurrentDuty finds the current duty person from a list of duties in the daily rotation.
+// People absent on currentDate are skipped, the rotation order of everyone else is kept.
+// This is a pure function for easy testing
+func FindCurrentDuty(duties []dao.Duty, currentDate time.Time, absences []dao.Absence) *dao.Duty {
+	duty, _ := FindShiftDuty(duties, currentDate, absences, Rotation{})
+	return duty
+}
+
+// FindShiftDuty finds the duty person for currentDate and the first day of their shift.
+// The last person on duty keeps the duty while their shift runs and they are not absent,
+// afterwards the next available person starts a new shift on currentDate.
 // This is a pure function for easy testing
-func FindCurrentDuty(duties []dao.Duty, currentDate time.Time) *dao.Duty {
+func FindShiftDuty(duties []dao.Duty, currentDate time.Time, absences []dao.Absence, rotation Rotation) (*dao.Duty, time.Time) {
 	if len(duties) == 0 {
-		return nil
+		return nil, currentDate
 	}
 
 	// Ensure duties are sorted by duty_id
@@ -99,7 +452,10 @@ func FindCurrentDuty(duties []dao.Duty, currentDate time.Time) *dao.Duty {
 	// Step 1: Find duty for today
 	for i := range duties {
 		if duties[i].LastDutyDate != nil && isSameDay(*duties[i].LastDutyDate, currentDate) {
-			return &duties[i]
+			if shiftStart := shiftStartOf(duties[i]); rotation.Continues(*shiftStart, currentDate) {
+				return &duties[i], *shiftStart
+			}
+			return &duties[i], currentDate
 		}
 	}
 
@@ -115,18 +471,21 @@ func FindCurrentDuty(duties []dao.Duty, currentDate time.Time) *dao.Duty {
 		}
 	}
 
-	// Step 3: Get next person alphabetically, or first if wrap around
-	if lastDutyIndex == -1 {
-		// No one has been on duty yet, return first
-		return &duties[0]
+	// Step 3: Keep the last duty person while their shift runs
+	if lastDutyIndex != -1 {
+		last := &duties[lastDutyIndex]
+		if shiftStart := shiftStartOf(*last); rotation.Continues(*shiftStart, currentDate) && !isAbsent(*last, currentDate, absences) {
+			return last, *shiftStart
+		}
 	}
 
-	nextIndex := (lastDutyIndex + 1) % len(duties)
-	return &duties[nextIndex]
+	// Step 4: Get next available person in the order of the rotation strategy
+	// If no one has been on duty yet, start from the first
+	return findAvailableAfter(duties, lastDutyIndex, currentDate, absences, rotation), currentDate
 }
 
-// FindNextDuty finds the next duty person after today's assigned duty.
-func FindNextDuty(duties []dao.Duty, currentDate time.Time) *dao.Duty {
+// FindNextDuty finds the next available duty person after today's assigned duty.
+func FindNextDuty(duties []dao.Duty, currentDate time.Time, absences []dao.Absence, rotation Rotation) *dao.Duty {
 	if len(duties) < 2 {
 		return nil
 	}
@@ -147,8 +506,103 @@ func FindNextDuty(duties []dao.Duty, currentDate time.Time) *dao.Duty {
 		return nil
 	}
 
-	nextIndex := (currentDutyIndex + 1) % len(duties)
-	return &duties[nextIndex]
+	next := findAvailableAfter(duties, currentDutyIndex, currentDate, absences, rotation)
+	if next == &duties[currentDutyIndex] {
+		return nil
+	}
+	return next
+}
+
+// FindSecondaryDuty finds the secondary duty person for currentDate and the first day of their shift
+// in the rotation tracked by LastSecondaryDutyDate. It follows the same rules as FindShiftDuty and never returns the primary.
+func FindSecondaryDuty(duties []dao.Duty, primary *dao.Duty, currentDate time.Time, absences []dao.Absence, period Rotation) (*dao.Duty, time.Time) {
+	if primary == nil {
+		return nil, currentDate
+	}
+	rotation := make([]dao.Duty, len(duties))
+	for i, duty := range duties {
+		rotation[i] = duty
+		rotation[i].LastDutyDate = duty.LastSecondaryDutyDate
+		rotation[i].ShiftStartDate = duty.SecondaryShiftStartDate
+	}
+	// The primary is unavailable as a backup, so it is skipped like an absent person
+	unavailable := append(slices.Clone(absences), dao.Absence{DutyRecordID: primary.ID, From: currentDate, To: currentDate})
+
+	secondary, shiftStart := FindShiftDuty(rotation, currentDate, unavailable, period)
+	if secondary != nil && secondary.ID == primary.ID {
+		// The primary already holds today's secondary date, the next available person takes it over
+		secondary = findAvailableAfter(rotation, slices.IndexFunc(rotation, func(duty dao.Duty) bool {
+			return duty.ID == primary.ID
+		}), currentDate, unavailable, period)
+		shiftStart = currentDate
+	}
+	if secondary == nil {
+		return nil, currentDate
+	}
+	for i := range duties {
+		if duties[i].ID == secondary.ID {
+			return &duties[i], shiftStart
+		}
+	}
+	return nil, currentDate
+}
+
+// FindEscalationCandidate walks the rotation after afterDutyID and returns the first person
+// who is not absent on currentDate and not listed in excluded
+func FindEscalationCandidate(duties []dao.Duty, afterDutyID string, currentDate time.Time, absences []dao.Absence, excluded []string, rotation Rotation) *dao.Duty {
+	// Start right after afterDutyID, or from the beginning when it is not in the rotation
+	after := slices.IndexFunc(duties, func(duty dao.Duty) bool {
+		return duty.DutyID == afterDutyID
+	})
+
+	skip := make(map[string]bool, len(excluded)+1)
+	skip[afterDutyID] = true
+	for _, dutyID := range excluded {
+		skip[dutyID] = true
+	}
+	for _, i := range rotation.order(duties, after, currentDate) {
+		candidate := &duties[i]
+		if !skip[candidate.DutyID] && !isAbsent(*candidate, currentDate, absences) {
+			return candidate
+		}
+	}
+	return nil
+}
+
+// findAvailableAfter walks the duties in the order of the rotation after index and returns the first person
+// who is not absent on currentDate, or nil if everybody is absent
+func findAvailableAfter(duties []dao.Duty, index int, currentDate time.Time, absences []dao.Absence, rotation Rotation) *dao.Duty {
+	for _, i := range rotation.order(duties, index, currentDate) {
+		if !isAbsent(duties[i], currentDate, absences) {
+			return &duties[i]
+		}
+	}
+	return nil
+}
+
+// ApplySwap returns the swap target when an accepted swap hands the duty of date over from duty,
+// otherwise it returns duty itself
+func ApplySwap(duties []dao.Duty, duty *dao.Duty, date time.Time, swaps []dao.Swap) *dao.Duty {
+	for _, swap := range swaps {
+		if swap.RequesterRecordID != duty.ID || !isSameDay(swap.Date, date) {
+			continue
+		}
+		for i := range duties {
+			if duties[i].ID == swap.TargetRecordID {
+				return &duties[i]
+			}
+		}
+	}
+	return duty
+}
+
+func isAbsent(duty dao.Duty, date time.Time, absences []dao.Absence) bool {
+	for _, absence := range absences {
+		if absence.DutyRecordID == duty.ID && absence.Covers(date) {
+			return true
+		}
+	}
+	return false
 }
 
 func isSameDay(t1, t2 time.Time) bool {
@@ -1,6 +1,7 @@
 package duty
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -8,7 +9,7 @@ import (
 )
 
 func TestFindCurrentDuty_EmptyList(t *testing.T) {
-	result := FindCurrentDuty([]dao.Duty{}, time.Now())
+	result := FindCurrentDuty([]dao.Duty{}, time.Now(), nil)
 	if result != nil {
 		t.Errorf("expected nil for empty list, got %+v", result)
 	}
@@ -24,7 +25,7 @@ func TestFindCurrentDuty_TodayExists(t *testing.T) {
 		{ID: 3, DutyID: "charlie", LastDutyDate: nil},
 	}
 
-	result := FindCurrentDuty(duties, today)
+	result := FindCurrentDuty(duties, today, nil)
 	if result == nil {
 		t.Fatal("expected duty, got nil")
 	}
@@ -43,7 +44,7 @@ func TestFindCurrentDuty_NextAlphabetically(t *testing.T) {
 		{ID: 3, DutyID: "charlie", LastDutyDate: nil},
 	}
 
-	result := FindCurrentDuty(duties, today)
+	result := FindCurrentDuty(duties, today, nil)
 	if result == nil {
 		t.Fatal("expected duty, got nil")
 	}
@@ -62,7 +63,7 @@ func TestFindCurrentDuty_WrapAround(t *testing.T) {
 		{ID: 3, DutyID: "charlie", LastDutyDate: &yesterday},
 	}
 
-	result := FindCurrentDuty(duties, today)
+	result := FindCurrentDuty(duties, today, nil)
 	if result == nil {
 		t.Fatal("expected duty, got nil")
 	}
@@ -80,7 +81,7 @@ func TestFindCurrentDuty_NoOneHasBeenOnDuty(t *testing.T) {
 		{ID: 3, DutyID: "bob", LastDutyDate: nil},
 	}
 
-	result := FindCurrentDuty(duties, today)
+	result := FindCurrentDuty(duties, today, nil)
 	if result == nil {
 		t.Fatal("expected duty, got nil")
 	}
@@ -97,7 +98,7 @@ func TestFindCurrentDuty_SinglePerson(t *testing.T) {
 		{ID: 1, DutyID: "alice", LastDutyDate: &yesterday},
 	}
 
-	result := FindCurrentDuty(duties, today)
+	result := FindCurrentDuty(duties, today, nil)
 	if result == nil {
 		t.Fatal("expected duty, got nil")
 	}
@@ -117,7 +118,7 @@ func TestFindCurrentDuty_UnsortedInput(t *testing.T) {
 		{ID: 2, DutyID: "bob", LastDutyDate: nil},
 	}
 
-	result := FindCurrentDuty(duties, today)
+	result := FindCurrentDuty(duties, today, nil)
 	if result == nil {
 		t.Fatal("expected duty, got nil")
 	}
@@ -137,7 +138,7 @@ func TestFindCurrentDuty_MultipleOldDates(t *testing.T) {
 		{ID: 3, DutyID: "charlie", LastDutyDate: nil},
 	}
 
-	result := FindCurrentDuty(duties, today)
+	result := FindCurrentDuty(duties, today, nil)
 	if result == nil {
 		t.Fatal("expected duty, got nil")
 	}
@@ -156,7 +157,7 @@ func TestFindCurrentDuty_SameDayDifferentTime(t *testing.T) {
 		{ID: 2, DutyID: "bob", LastDutyDate: nil},
 	}
 
-	result := FindCurrentDuty(duties, today)
+	result := FindCurrentDuty(duties, today, nil)
 	if result == nil {
 		t.Fatal("expected duty, got nil")
 	}
@@ -176,7 +177,7 @@ func TestFindNextDuty_AfterTodayDuty(t *testing.T) {
 		{ID: 3, DutyID: "charlie", LastDutyDate: nil},
 	}
 
-	result := FindNextDuty(duties, today)
+	result := FindNextDuty(duties, today, nil, Rotation{})
 	if result == nil {
 		t.Fatal("expected duty, got nil")
 	}
@@ -194,7 +195,7 @@ func TestFindNextDuty_WrapAround(t *testing.T) {
 		{ID: 3, DutyID: "charlie", LastDutyDate: &today},
 	}
 
-	result := FindNextDuty(duties, today)
+	result := FindNextDuty(duties, today, nil, Rotation{})
 	if result == nil {
 		t.Fatal("expected duty, got nil")
 	}
@@ -212,7 +213,7 @@ func TestFindNextDuty_NoTodayDuty(t *testing.T) {
 		{ID: 2, DutyID: "bob", LastDutyDate: nil},
 	}
 
-	result := FindNextDuty(duties, today)
+	result := FindNextDuty(duties, today, nil, Rotation{})
 	if result != nil {
 		t.Errorf("expected nil when nobody is assigned today, got %+v", result)
 	}
@@ -225,8 +226,657 @@ func TestFindNextDuty_SinglePerson(t *testing.T) {
 		{ID: 1, DutyID: "alice", LastDutyDate: &today},
 	}
 
-	result := FindNextDuty(duties, today)
+	result := FindNextDuty(duties, today, nil, Rotation{})
 	if result != nil {
 		t.Errorf("expected nil for single person, got %+v", result)
 	}
 }
+
+func TestService_GetCurrentDuty_AssignsOncePerDay(t *testing.T) {
+	repo := dao.NewMemoryRepository()
+	service := NewService(repo)
+	yesterday := service.today().AddDate(0, 0, -1)
+	repo.AddDuty("alice", &yesterday)
+	repo.AddDuty("bob", nil)
+
+	first, err := service.GetCurrentDuty("caller")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first == nil || first.DutyID != "bob" || !first.IsNewAssignment {
+		t.Fatalf("expected new assignment for bob, got %+v", first)
+	}
+
+	second, err := service.GetCurrentDuty("caller")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if second == nil || second.DutyID != "bob" || second.IsNewAssignment {
+		t.Fatalf("expected existing assignment for bob, got %+v", second)
+	}
+}
+
+func TestService_GetNextDuty_ReassignsToday(t *testing.T) {
+	repo := dao.NewMemoryRepository()
+	repo.AddDuty("alice", nil)
+	repo.AddDuty("bob", nil)
+	service := NewService(repo)
+
+	if _, err := service.GetCurrentDuty("caller"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	next, err := service.GetNextDuty("admin")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next == nil || next.DutyID != "bob" {
+		t.Fatalf("expected bob after next, got %+v", next)
+	}
+
+	current, err := service.GetCurrentDuty("caller")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if current == nil || current.DutyID != "bob" || current.IsNewAssignment {
+		t.Fatalf("expected bob to stay on duty, got %+v", current)
+	}
+}
+
+func TestService_TodayInLocation(t *testing.T) {
+	clock := &fixedClock{now: time.Date(2026, 3, 10, 10, 30, 0, 0, time.UTC)}
+	for name, want := range map[string]time.Time{
+		"Pacific/Kiritimati": time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC),
+		"UTC":                time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC),
+		"Pacific/Pago_Pago":  time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC),
+	} {
+		// the rotation and the clock must not reset the location
+		zoned := NewService(dao.NewMemoryRepository()).
+			WithLocation(mustLoadLocation(t, name)).
+			WithRotation(Rotation{Period: PeriodWeekly}).
+			WithClock(clock)
+
+		if today := zoned.today(); !today.Equal(want) || today.Location() != time.UTC {
+			t.Errorf("%s: expected %v, got %v", name, want, today)
+		}
+	}
+}
+
+func TestService_PeekCurrentDuty_AssignsNobody(t *testing.T) {
+	repo := dao.NewMemoryRepository()
+	service := NewService(repo)
+	yesterday := service.today().AddDate(0, 0, -1)
+	repo.AddDuty("alice", &yesterday)
+	repo.AddDuty("bob", nil)
+
+	peeked, err := service.PeekCurrentDuty()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if peeked == nil || peeked.DutyID != "bob" || peeked.IsNewAssignment {
+		t.Fatalf("expected bob without a new assignment, got %+v", peeked)
+	}
+	if history, _ := service.GetHistory(1); len(history) != 0 {
+		t.Errorf("expected no history, got %+v", history)
+	}
+	if bob, _ := repo.GetDutyByDutyID("bob"); bob.LastDutyDate != nil {
+		t.Errorf("expected bob to stay unassigned, got %v", *bob.LastDutyDate)
+	}
+
+	if current, _ := service.GetCurrentDuty("caller"); current == nil || current.DutyID != "bob" || !current.IsNewAssignment {
+		t.Errorf("expected the first call to assign bob, got %+v", current)
+	}
+}
+
+func TestService_GetCurrentDuty_NoDuties(t *testing.T) {
+	service := NewService(dao.NewMemoryRepository())
+
+	result, err := service.GetCurrentDuty("caller")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != nil {
+		t.Errorf("expected nil result without duties, got %+v", result)
+	}
+}
+
+func TestService_GetHistory_RecordsActorAndReason(t *testing.T) {
+	repo := dao.NewMemoryRepository()
+	repo.AddDuty("alice", nil)
+	repo.AddDuty("bob", nil)
+	service := NewService(repo)
+
+	if _, err := service.GetCurrentDuty("caller"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.GetNextDuty("admin"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries, err := service.GetHistory(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entries = primaryEntries(entries)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 history entries, got %d", len(entries))
+	}
+	if entries[0].DutyID != "bob" || entries[0].Reason != dao.ReasonNext || entries[0].ActorUserID != "admin" || entries[0].ReplacedDutyID != "alice" {
+		t.Errorf("unexpected next entry: %+v", entries[0])
+	}
+	if entries[1].DutyID != "alice" || entries[1].Reason != dao.ReasonRotation || entries[1].ActorUserID != "caller" {
+		t.Errorf("unexpected rotation entry: %+v", entries[1])
+	}
+}
+
+func TestFindCurrentDuty_SkipsAbsentPerson(t *testing.T) {
+	today := time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC)
+	yesterday := today.AddDate(0, 0, -1)
+
+	duties := []dao.Duty{
+		{ID: 1, DutyID: "alice", LastDutyDate: &yesterday},
+		{ID: 2, DutyID: "bob", LastDutyDate: nil},
+		{ID: 3, DutyID: "charlie", LastDutyDate: nil},
+	}
+	absences := []dao.Absence{
+		{DutyRecordID: 2, From: yesterday, To: today.AddDate(0, 0, 3)},
+	}
+
+	result := FindCurrentDuty(duties, today, absences)
+	if result == nil {
+		t.Fatal("expected duty, got nil")
+	}
+	if result.DutyID != "charlie" {
+		t.Errorf("expected charlie (bob is away), got %s", result.DutyID)
+	}
+}
+
+func TestFindCurrentDuty_AbsenceOutsideDateIgnored(t *testing.T) {
+	today := time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC)
+	yesterday := today.AddDate(0, 0, -1)
+
+	duties := []dao.Duty{
+		{ID: 1, DutyID: "alice", LastDutyDate: &yesterday},
+		{ID: 2, DutyID: "bob", LastDutyDate: nil},
+	}
+	absences := []dao.Absence{
+		{DutyRecordID: 2, From: today.AddDate(0, 0, 1), To: today.AddDate(0, 0, 5)},
+	}
+
+	result := FindCurrentDuty(duties, today, absences)
+	if result == nil || result.DutyID != "bob" {
+		t.Errorf("expected bob (absence starts tomorrow), got %+v", result)
+	}
+}
+
+func TestFindCurrentDuty_EverybodyAbsent(t *testing.T) {
+	today := time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC)
+
+	duties := []dao.Duty{
+		{ID: 1, DutyID: "alice", LastDutyDate: nil},
+		{ID: 2, DutyID: "bob", LastDutyDate: nil},
+	}
+	absences := []dao.Absence{
+		{DutyRecordID: 1, From: today, To: today},
+		{DutyRecordID: 2, From: today, To: today},
+	}
+
+	if result := FindCurrentDuty(duties, today, absences); result != nil {
+		t.Errorf("expected nil when everybody is absent, got %+v", result)
+	}
+}
+
+func TestFindNextDuty_SkipsAbsentPerson(t *testing.T) {
+	today := time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC)
+
+	duties := []dao.Duty{
+		{ID: 1, DutyID: "alice", LastDutyDate: &today},
+		{ID: 2, DutyID: "bob", LastDutyDate: nil},
+		{ID: 3, DutyID: "charlie", LastDutyDate: nil},
+	}
+	absences := []dao.Absence{
+		{DutyRecordID: 2, From: today, To: today},
+	}
+
+	result := FindNextDuty(duties, today, absences, Rotation{})
+	if result == nil || result.DutyID != "charlie" {
+		t.Errorf("expected charlie (bob is away), got %+v", result)
+	}
+}
+
+func TestFindNextDuty_OnlyAbsentOthers(t *testing.T) {
+	today := time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC)
+
+	duties := []dao.Duty{
+		{ID: 1, DutyID: "alice", LastDutyDate: &today},
+		{ID: 2, DutyID: "bob", LastDutyDate: nil},
+	}
+	absences := []dao.Absence{
+		{DutyRecordID: 2, From: today, To: today},
+	}
+
+	if result := FindNextDuty(duties, today, absences, Rotation{}); result != nil {
+		t.Errorf("expected nil when everybody else is away, got %+v", result)
+	}
+}
+
+func TestService_AbsenceSkipsAndBackRestores(t *testing.T) {
+	repo := dao.NewMemoryRepository()
+	repo.AddDuty("alice", nil)
+	repo.AddDuty("bob", nil)
+	service := NewService(repo)
+	today := service.today()
+
+	if _, err := service.AddAbsence("alice", today, today.AddDate(0, 0, 7), "vacation"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	current, err := service.GetCurrentDuty("caller")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if current == nil || current.DutyID != "bob" {
+		t.Fatalf("expected bob while alice is away, got %+v", current)
+	}
+
+	changed, err := service.EndAbsence("alice")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if changed != 1 {
+		t.Errorf("expected 1 absence to be ended, got %d", changed)
+	}
+	absences, _ := repo.GetAbsences(today, today.AddDate(0, 0, 7))
+	if len(absences) != 0 {
+		t.Errorf("expected no absences after return, got %+v", absences)
+	}
+}
+
+func TestService_AddAbsence_UnknownUser(t *testing.T) {
+	service := NewService(dao.NewMemoryRepository())
+	today := service.today()
+
+	if _, err := service.AddAbsence("stranger", today, today, ""); !errors.Is(err, ErrNotInRotation) {
+		t.Errorf("expected ErrNotInRotation, got %v", err)
+	}
+}
+
+func TestApplySwap(t *testing.T) {
+	today := time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC)
+	duties := []dao.Duty{
+		{ID: 1, DutyID: "alice"},
+		{ID: 2, DutyID: "bob"},
+	}
+	swaps := []dao.Swap{{RequesterRecordID: 1, TargetRecordID: 2, Date: today, Status: dao.SwapAccepted}}
+
+	if result := ApplySwap(duties, &duties[0], today, swaps); result.DutyID != "bob" {
+		t.Errorf("expected bob to take alice's duty, got %s", result.DutyID)
+	}
+	if result := ApplySwap(duties, &duties[0], today.AddDate(0, 0, 1), swaps); result.DutyID != "alice" {
+		t.Errorf("expected swap to apply only to its date, got %s", result.DutyID)
+	}
+	if result := ApplySwap(duties, &duties[1], today, swaps); result.DutyID != "bob" {
+		t.Errorf("expected swap to apply only to the requester, got %s", result.DutyID)
+	}
+}
+
+func TestService_AcceptedSwapIsHonoured(t *testing.T) {
+	repo := dao.NewMemoryRepository()
+	service := NewService(repo)
+	today := service.today()
+	yesterday := today.AddDate(0, 0, -1)
+	repo.AddDuty("alice", &yesterday)
+	repo.AddDuty("bob", nil)
+	repo.AddDuty("charlie", nil)
+
+	swap, err := service.RequestSwap("bob", today, "charlie", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if swap.Status != dao.SwapPending {
+		t.Errorf("expected pending swap, got %s", swap.Status)
+	}
+	if _, err := service.ResolveSwap("charlie", 0, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	current, err := service.GetCurrentDuty("caller")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if current == nil || current.DutyID != "charlie" || !current.IsNewAssignment {
+		t.Fatalf("expected charlie to take bob's day, got %+v", current)
+	}
+
+	again, _ := service.GetCurrentDuty("caller")
+	if again == nil || again.DutyID != "charlie" || again.IsNewAssignment {
+		t.Fatalf("expected charlie to stay on duty, got %+v", again)
+	}
+
+	entries, _ := service.GetHistory(1)
+	entries = primaryEntries(entries)
+	if len(entries) != 1 || entries[0].DutyID != "charlie" || entries[0].ReplacedDutyID != "bob" || entries[0].Reason != dao.ReasonSwap {
+		t.Errorf("unexpected history after swap: %+v", entries)
+	}
+
+	// the rotation continues after bob, so charlie is not skipped tomorrow
+	duties, _ := repo.GetAllDuties()
+	next := FindCurrentDuty(duties, today.AddDate(0, 0, 1), nil)
+	if next == nil || next.DutyID != "charlie" {
+		t.Errorf("expected charlie to be next in rotation, got %+v", next)
+	}
+}
+
+func TestService_GetNextDuty_WithAcceptedSwapRecordsSkippedPerson(t *testing.T) {
+	repo := dao.NewMemoryRepository()
+	repo.AddDuty("alice", nil)
+	bob := repo.AddDuty("bob", nil)
+	carol := repo.AddDuty("carol", nil)
+	service := NewService(repo)
+	today := service.today()
+
+	if current, _ := service.GetCurrentDuty("caller"); current == nil || current.DutyID != "alice" {
+		t.Fatalf("expected alice on duty, got %+v", current)
+	}
+	// bob swapped the day while he was due to take it, RequestSwap refuses it now that alice is on duty
+	if _, err := repo.CreateSwap(dao.Swap{Date: today, RequesterRecordID: bob.ID, RequesterDutyID: "bob",
+		TargetRecordID: carol.ID, TargetDutyID: "carol"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := service.ResolveSwap("carol", 0, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	next, err := service.GetNextDuty("admin")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next == nil || next.DutyID != "carol" {
+		t.Fatalf("expected carol to take bob's turn, got %+v", next)
+	}
+
+	history, _ := service.GetHistory(1)
+	history = primaryEntries(history)
+	if len(history) != 2 || history[0].DutyID != "carol" || history[0].ReplacedDutyID != "alice" || history[0].Reason != dao.ReasonNext {
+		t.Fatalf("expected carol to replace alice, got %+v", history)
+	}
+	duties, _ := repo.GetAllDuties()
+	for _, stats := range ComputeStats(duties, history, nil, nil) {
+		expected := 0
+		if stats.DutyID == "alice" {
+			expected = 1
+		}
+		if stats.NextSkips != expected {
+			t.Errorf("expected %d skips for %s, got %d", expected, stats.DutyID, stats.NextSkips)
+		}
+	}
+}
+
+func TestService_DeclinedSwapIsIgnored(t *testing.T) {
+	repo := dao.NewMemoryRepository()
+	repo.AddDuty("alice", nil)
+	repo.AddDuty("bob", nil)
+	service := NewService(repo)
+	today := service.today()
+
+	if _, err := service.RequestSwap("alice", today, "bob", nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	swap, err := service.ResolveSwap("bob", 0, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if swap.Status != dao.SwapDeclined {
+		t.Errorf("expected declined swap, got %s", swap.Status)
+	}
+
+	current, _ := service.GetCurrentDuty("caller")
+	if current == nil || current.DutyID != "alice" {
+		t.Fatalf("expected alice to stay on duty, got %+v", current)
+	}
+	if _, err := service.ResolveSwap("bob", 0, true); !errors.Is(err, ErrNoPendingSwap) {
+		t.Errorf("expected ErrNoPendingSwap once resolved, got %v", err)
+	}
+}
+
+func TestService_RequestSwap_Validation(t *testing.T) {
+	repo := dao.NewMemoryRepository()
+	repo.AddDuty("alice", nil)
+	service := NewService(repo)
+	today := service.today()
+
+	if _, err := service.RequestSwap("alice", today, "alice", nil); !errors.Is(err, ErrSwapWithSelf) {
+		t.Errorf("expected ErrSwapWithSelf, got %v", err)
+	}
+	if _, err := service.RequestSwap("alice", today.AddDate(0, 0, -1), "bob", nil); !errors.Is(err, ErrSwapInPast) {
+		t.Errorf("expected ErrSwapInPast, got %v", err)
+	}
+	if _, err := service.RequestSwap("stranger", today, "alice", nil); !errors.Is(err, ErrNotInRotation) {
+		t.Errorf("expected ErrNotInRotation, got %v", err)
+	}
+	if _, err := service.RequestSwap("alice", today, "stranger", nil); !errors.Is(err, ErrSwapTargetNotInRotation) {
+		t.Errorf("expected ErrSwapTargetNotInRotation, got %v", err)
+	}
+}
+
+func TestService_RequestSwap_RequiresRequesterOnDuty(t *testing.T) {
+	repo := dao.NewMemoryRepository()
+	service := NewService(repo)
+	today := service.today()
+	yesterday := today.AddDate(0, 0, -1)
+	repo.AddDuty("alice", &yesterday)
+	repo.AddDuty("bob", nil)
+	repo.AddDuty("carol", nil)
+
+	if _, err := service.RequestSwap("carol", today, "alice", nil); !errors.Is(err, ErrSwapNotOnDuty) {
+		t.Errorf("expected ErrSwapNotOnDuty for a day of bob, got %v", err)
+	}
+	if _, err := service.RequestSwap("carol", today.AddDate(0, 0, 1), "alice", nil); err != nil {
+		t.Errorf("expected carol to swap her own day, got %v", err)
+	}
+
+	// carol's turn falls on the day after tomorrow when tomorrow is not a working day
+	dayOff := today.AddDate(0, 0, 1)
+	isWorkingDay := func(date time.Time) bool { return !isSameDay(date, dayOff) }
+	if _, err := service.RequestSwap("carol", dayOff, "alice", isWorkingDay); !errors.Is(err, ErrSwapNotOnDuty) {
+		t.Errorf("expected ErrSwapNotOnDuty for a day off, got %v", err)
+	}
+	if _, err := service.RequestSwap("carol", today.AddDate(0, 0, 2), "alice", isWorkingDay); err != nil {
+		t.Errorf("expected carol to swap her day after the day off, got %v", err)
+	}
+	if _, err := service.RequestSwap("bob", today.AddDate(3, 0, 0), "alice", nil); !errors.Is(err, ErrSwapTooFar) {
+		t.Errorf("expected ErrSwapTooFar, got %v", err)
+	}
+}
+
+func TestService_RequestSwap_RejectsAbsentTarget(t *testing.T) {
+	repo := dao.NewMemoryRepository()
+	service := NewService(repo)
+	today := service.today()
+	yesterday := today.AddDate(0, 0, -1)
+	repo.AddDuty("alice", &yesterday)
+	repo.AddDuty("bob", nil)
+	carol := repo.AddDuty("carol", nil)
+	if _, err := repo.AddAbsence(dao.Absence{DutyRecordID: carol.ID, From: today, To: today, Reason: "vacation"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := service.RequestSwap("bob", today, "carol", nil); !errors.Is(err, ErrSwapTargetAbsent) {
+		t.Errorf("expected ErrSwapTargetAbsent, got %v", err)
+	}
+	if _, err := service.RequestSwap("bob", today, "alice", nil); err != nil {
+		t.Errorf("expected a swap with a present target, got %v", err)
+	}
+}
+
+func TestFindEscalationCandidate(t *testing.T) {
+	today := time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC)
+
+	duties := []dao.Duty{
+		{ID: 1, DutyID: "alice", LastDutyDate: &today},
+		{ID: 2, DutyID: "bob"},
+		{ID: 3, DutyID: "charlie"},
+		{ID: 4, DutyID: "dave"},
+	}
+	absences := []dao.Absence{
+		{DutyRecordID: 2, From: today, To: today},
+	}
+
+	tests := []struct {
+		name     string
+		after    string
+		excluded []string
+		want     string
+	}{
+		{"skips absent person", "alice", nil, "charlie"},
+		{"skips already notified", "charlie", []string{"alice"}, "dave"},
+		{"wraps around", "dave", nil, "alice"},
+		{"unknown person starts from the beginning", "zoe", nil, "alice"},
+		{"nobody left", "alice", []string{"charlie", "dave"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			if candidate := FindEscalationCandidate(duties, tt.after, today, absences, tt.excluded, Rotation{}); candidate != nil {
+				got = candidate.DutyID
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// primaryEntries drops the history of the secondary rotation
+func primaryEntries(entries []dao.HistoryEntry) []dao.HistoryEntry {
+	var primary []dao.HistoryEntry
+	for _, entry := range entries {
+		if entry.Role != dao.RoleSecondary {
+			primary = append(primary, entry)
+		}
+	}
+	return primary
+}
+
+func TestFindSecondaryDuty(t *testing.T) {
+	today := time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC)
+	yesterday := today.AddDate(0, 0, -1)
+
+	tests := []struct {
+		name      string
+		duties    []dao.Duty
+		primaryID int64
+		absences  []dao.Absence
+		want      string
+	}{
+		{
+			name: "independent rotation continues after yesterday's secondary",
+			duties: []dao.Duty{
+				{ID: 1, DutyID: "alice", LastDutyDate: &today},
+				{ID: 2, DutyID: "bob", LastSecondaryDutyDate: &yesterday},
+				{ID: 3, DutyID: "charlie"},
+			},
+			primaryID: 1,
+			want:      "charlie",
+		},
+		{
+			name: "primary is skipped",
+			duties: []dao.Duty{
+				{ID: 1, DutyID: "alice", LastSecondaryDutyDate: &yesterday},
+				{ID: 2, DutyID: "bob", LastDutyDate: &today},
+				{ID: 3, DutyID: "charlie"},
+			},
+			primaryID: 2,
+			want:      "charlie",
+		},
+		{
+			name: "today's secondary is kept",
+			duties: []dao.Duty{
+				{ID: 1, DutyID: "alice", LastDutyDate: &today},
+				{ID: 2, DutyID: "bob", LastSecondaryDutyDate: &today},
+				{ID: 3, DutyID: "charlie"},
+			},
+			primaryID: 1,
+			want:      "bob",
+		},
+		{
+			name: "primary holding today's secondary date hands it over",
+			duties: []dao.Duty{
+				{ID: 1, DutyID: "alice"},
+				{ID: 2, DutyID: "bob", LastDutyDate: &today, LastSecondaryDutyDate: &today},
+				{ID: 3, DutyID: "charlie"},
+			},
+			primaryID: 2,
+			want:      "charlie",
+		},
+		{
+			name: "absent people are skipped",
+			duties: []dao.Duty{
+				{ID: 1, DutyID: "alice", LastDutyDate: &today},
+				{ID: 2, DutyID: "bob"},
+				{ID: 3, DutyID: "charlie"},
+			},
+			primaryID: 1,
+			absences:  []dao.Absence{{DutyRecordID: 2, From: today, To: today}},
+			want:      "charlie",
+		},
+		{
+			name:      "nobody but the primary",
+			duties:    []dao.Duty{{ID: 1, DutyID: "alice", LastDutyDate: &today}},
+			primaryID: 1,
+			want:      "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var primary *dao.Duty
+			for i := range tt.duties {
+				if tt.duties[i].ID == tt.primaryID {
+					primary = &tt.duties[i]
+				}
+			}
+			got := ""
+			if secondary, _ := FindSecondaryDuty(tt.duties, primary, today, tt.absences, Rotation{}); secondary != nil {
+				got = secondary.DutyID
+			}
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestService_GetCurrentDuty_AssignsSecondary(t *testing.T) {
+	repo := dao.NewMemoryRepository()
+	repo.AddDuty("alice", nil)
+	repo.AddDuty("bob", nil)
+	repo.AddDuty("charlie", nil)
+	service := NewService(repo)
+
+	result, err := service.GetCurrentDuty("caller")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.DutyID != "alice" || result.SecondaryDutyID != "bob" {
+		t.Fatalf("expected alice with bob as backup, got %+v", result)
+	}
+
+	// \next moves the primary to bob, so the backup moves on
+	next, err := service.GetNextDuty("admin")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next.DutyID != "bob" || next.SecondaryDutyID != "charlie" {
+		t.Fatalf("expected bob with charlie as backup, got %+v", next)
+	}
+
+	again, _ := service.GetCurrentDuty("caller")
+	if again.DutyID != "bob" || again.SecondaryDutyID != "charlie" {
+		t.Fatalf("expected assignments to be stable, got %+v", again)
+	}
+
+	entries, _ := service.GetHistory(1)
+	if entries[0].Role != dao.RoleSecondary || entries[0].DutyID != "charlie" || entries[0].ReplacedDutyID != "bob" {
+		t.Errorf("expected backup handover in the history, got %+v", entries[0])
+	}
+}
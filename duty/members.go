@@ -0,0 +1,42 @@
+package duty
+
+import (
+	"watch_bot/dao"
+)
+
+// GetMembers returns everybody who has ever been in the rotation, inactive members included
+func (s *Service) GetMembers() ([]dao.Duty, error) {
+	return s.repository.GetMembers()
+}
+
+// AddMember puts dutyID into the rotation. A former member is reactivated with their history,
+// which is reported by reactivated.
+func (s *Service) AddMember(dutyID string) (reactivated bool, err error) {
+	members, err := s.repository.GetMembers()
+	if err != nil {
+		return false, err
+	}
+	for _, member := range members {
+		if member.DutyID != dutyID {
+			continue
+		}
+		if member.Active {
+			return false, ErrAlreadyMember
+		}
+		return true, s.repository.SetActive(member.ID, true)
+	}
+	_, err = s.repository.AddMember(dutyID)
+	return false, err
+}
+
+// RemoveMember takes dutyID out of the rotation, the member and their history are kept
+func (s *Service) RemoveMember(dutyID string) error {
+	member, err := s.repository.GetDutyByDutyID(dutyID)
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		return ErrNotInRotation
+	}
+	return s.repository.SetActive(member.ID, false)
+}
@@ -0,0 +1,46 @@
+package duty
+
+import (
+	"errors"
+	"testing"
+
+	"watch_bot/dao"
+)
+
+func TestService_Members(t *testing.T) {
+	repo := dao.NewMemoryRepository()
+	repo.AddDuty("alice", nil)
+	service := NewService(repo)
+
+	if reactivated, err := service.AddMember("bob"); err != nil || reactivated {
+		t.Fatalf("expected bob to be added, got reactivated=%v, err=%v", reactivated, err)
+	}
+	if _, err := service.AddMember("bob"); !errors.Is(err, ErrAlreadyMember) {
+		t.Errorf("expected ErrAlreadyMember, got %v", err)
+	}
+	if err := service.RemoveMember("alice"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := service.RemoveMember("alice"); !errors.Is(err, ErrNotInRotation) {
+		t.Errorf("expected ErrNotInRotation for an inactive member, got %v", err)
+	}
+
+	result, err := service.GetCurrentDuty("caller")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result == nil || result.DutyID != "bob" {
+		t.Fatalf("expected bob to be the only one on duty, got %+v", result)
+	}
+
+	if reactivated, err := service.AddMember("alice"); err != nil || !reactivated {
+		t.Fatalf("expected alice to be reactivated, got reactivated=%v, err=%v", reactivated, err)
+	}
+	members, err := service.GetMembers()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(members) != 2 || !members[0].Active || !members[1].Active {
+		t.Errorf("expected two active members, got %+v", members)
+	}
+}
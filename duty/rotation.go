@@ -0,0 +1,132 @@
+package duty
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"watch_bot/dao"
+)
+
+// Period is the length of a duty shift
+type Period int
+
+const (
+	// PeriodDaily hands the duty over every day
+	PeriodDaily Period = iota
+	// PeriodWeekly hands the duty over on the handover weekday
+	PeriodWeekly
+	// PeriodWorkingDays hands the duty over after a number of working days
+	PeriodWorkingDays
+)
+
+// maxShiftDays bounds custom shifts so that counting working days stays cheap
+const maxShiftDays = 60
+
+// Rotation describes how long a duty shift lasts and who takes the next one.
+// The zero value is the daily alphabetical rotation.
+type Rotation struct {
+	Period Period
+	// HandoverDay is the first day of a weekly shift
+	HandoverDay time.Weekday
+	// ShiftDays is the number of working days in a PeriodWorkingDays shift
+	ShiftDays int
+	// IsWorkingDay tells which days count towards ShiftDays, nil counts every day
+	IsWorkingDay func(time.Time) bool
+	// Strategy orders the people, nil is AlphabeticalStrategy
+	Strategy RotationStrategy
+}
+
+// ParseRotation parses a rotation period: "daily", "weekly" (handover on Monday),
+// "weekly:<weekday>" or "days:<N>" for shifts of N working days. An empty value is daily.
+func ParseRotation(value string) (Rotation, error) {
+	kind, arg, hasArg := strings.Cut(strings.ToLower(strings.TrimSpace(value)), ":")
+	switch {
+	case kind == "" || kind == "daily" && !hasArg:
+		return Rotation{Period: PeriodDaily}, nil
+	case kind == "weekly":
+		if !hasArg {
+			return Rotation{Period: PeriodWeekly, HandoverDay: time.Monday}, nil
+		}
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			if strings.EqualFold(day.String(), arg) {
+				return Rotation{Period: PeriodWeekly, HandoverDay: day}, nil
+			}
+		}
+		return Rotation{}, fmt.Errorf("unknown handover weekday %q", arg)
+	case kind == "days" && hasArg:
+		days, err := strconv.Atoi(arg)
+		if err != nil || days < 1 || days > maxShiftDays {
+			return Rotation{}, fmt.Errorf("shift length must be a number of working days between 1 and %d, got %q", maxShiftDays, arg)
+		}
+		return Rotation{Period: PeriodWorkingDays, ShiftDays: days}, nil
+	}
+	return Rotation{}, fmt.Errorf("unknown rotation period %q", value)
+}
+
+// String returns the rotation in the format accepted by ParseRotation
+func (r Rotation) String() string {
+	switch r.Period {
+	case PeriodWeekly:
+		return "weekly:" + strings.ToLower(r.HandoverDay.String())
+	case PeriodWorkingDays:
+		return "days:" + strconv.Itoa(r.ShiftDays)
+	default:
+		return "daily"
+	}
+}
+
+// order returns indexes of duties in the order of the rotation strategy after last
+func (r Rotation) order(duties []dao.Duty, last int, date time.Time) []int {
+	if r.Strategy == nil {
+		return AlphabeticalStrategy{}.Order(duties, last, date)
+	}
+	return r.Strategy.Order(duties, last, date)
+}
+
+// Continues reports whether a shift that started on shiftStart still runs on date
+func (r Rotation) Continues(shiftStart, date time.Time) bool {
+	if shiftStart.After(date) {
+		return false
+	}
+	switch r.Period {
+	case PeriodWeekly:
+		// The shift runs until the first handover day after its start
+		offset := (int(date.Weekday()) - int(r.HandoverDay) + 7) % 7
+		lastHandover := date.AddDate(0, 0, -offset)
+		return !dayOf(shiftStart).Before(dayOf(lastHandover))
+	case PeriodWorkingDays:
+		return r.workingDaysBetween(shiftStart, date) <= r.ShiftDays
+	default:
+		return isSameDay(shiftStart, date)
+	}
+}
+
+// workingDaysBetween counts working days from shiftStart to date inclusive, it stops counting
+// once the shift is over. A shift that starts on a day off still counts that day.
+func (r Rotation) workingDaysBetween(shiftStart, date time.Time) int {
+	count := 1
+	day := dayOf(shiftStart).AddDate(0, 0, 1)
+	end := dayOf(date)
+	for !day.After(end) && count <= r.ShiftDays {
+		if r.IsWorkingDay == nil || r.IsWorkingDay(day) {
+			count++
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return count
+}
+
+// shiftStartOf returns the first day of the current shift of the duty, records without it
+// started their shift on the last duty date
+func shiftStartOf(duty dao.Duty) *time.Time {
+	if duty.ShiftStartDate != nil {
+		return duty.ShiftStartDate
+	}
+	return duty.LastDutyDate
+}
+
+func dayOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,258 @@
+package duty
+
+import (
+	"testing"
+	"time"
+
+	"watch_bot/dao"
+)
+
+func TestParseRotation(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Rotation
+		wantErr bool
+	}{
+		{"", Rotation{Period: PeriodDaily}, false},
+		{"daily", Rotation{Period: PeriodDaily}, false},
+		{"weekly", Rotation{Period: PeriodWeekly, HandoverDay: time.Monday}, false},
+		{"Weekly:Wednesday", Rotation{Period: PeriodWeekly, HandoverDay: time.Wednesday}, false},
+		{"days:3", Rotation{Period: PeriodWorkingDays, ShiftDays: 3}, false},
+		{"weekly:someday", Rotation{}, true},
+		{"days:0", Rotation{}, true},
+		{"days:many", Rotation{}, true},
+		{"days", Rotation{}, true},
+		{"monthly", Rotation{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseRotation(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRotation(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got.Period != tt.want.Period || got.HandoverDay != tt.want.HandoverDay || got.ShiftDays != tt.want.ShiftDays {
+				t.Errorf("ParseRotation(%q) = %+v, want %+v", tt.value, got, tt.want)
+			}
+			if !tt.wantErr && tt.value != "" {
+				if again, _ := ParseRotation(got.String()); again.String() != got.String() {
+					t.Errorf("String() %q does not parse back", got.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRotation_Continues(t *testing.T) {
+	weekdaysOnly := func(date time.Time) bool {
+		return date.Weekday() != time.Saturday && date.Weekday() != time.Sunday
+	}
+	// 2026-01-05 is a Monday
+	day := func(d int) time.Time {
+		return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name       string
+		rotation   Rotation
+		shiftStart time.Time
+		date       time.Time
+		want       bool
+	}{
+		{"daily same day", Rotation{}, day(5), day(5), true},
+		{"daily next day", Rotation{}, day(5), day(6), false},
+		{"weekly within week", Rotation{Period: PeriodWeekly, HandoverDay: time.Monday}, day(5), day(9), true},
+		{"weekly over the weekend", Rotation{Period: PeriodWeekly, HandoverDay: time.Monday}, day(5), day(11), true},
+		{"weekly on handover day", Rotation{Period: PeriodWeekly, HandoverDay: time.Monday}, day(5), day(12), false},
+		{"weekly started mid-week", Rotation{Period: PeriodWeekly, HandoverDay: time.Monday}, day(7), day(9), true},
+		{"weekly handover on wednesday", Rotation{Period: PeriodWeekly, HandoverDay: time.Wednesday}, day(5), day(6), true},
+		{"weekly handover on wednesday passed", Rotation{Period: PeriodWeekly, HandoverDay: time.Wednesday}, day(5), day(7), false},
+		{"working days within shift", Rotation{Period: PeriodWorkingDays, ShiftDays: 3, IsWorkingDay: weekdaysOnly}, day(8), day(12), true},
+		{"working days skip weekend", Rotation{Period: PeriodWorkingDays, ShiftDays: 3, IsWorkingDay: weekdaysOnly}, day(8), day(13), false},
+		{"working days without calendar", Rotation{Period: PeriodWorkingDays, ShiftDays: 3}, day(8), day(10), true},
+		{"working days without calendar over", Rotation{Period: PeriodWorkingDays, ShiftDays: 3}, day(8), day(11), false},
+		{"shift in the future", Rotation{Period: PeriodWeekly, HandoverDay: time.Monday}, day(9), day(8), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rotation.Continues(tt.shiftStart, tt.date); got != tt.want {
+				t.Errorf("Continues(%s, %s) = %v, want %v", tt.shiftStart.Format("Mon 02"), tt.date.Format("Mon 02"), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindShiftDuty(t *testing.T) {
+	weekly := Rotation{Period: PeriodWeekly, HandoverDay: time.Monday}
+	monday := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	tuesday := monday.AddDate(0, 0, 1)
+	thursday := monday.AddDate(0, 0, 3)
+	lastFriday := monday.AddDate(0, 0, -3)
+	nextMonday := monday.AddDate(0, 0, 7)
+
+	tests := []struct {
+		name           string
+		rotation       Rotation
+		duties         []dao.Duty
+		absences       []dao.Absence
+		date           time.Time
+		wantDutyID     string
+		wantShiftStart time.Time
+	}{
+		{
+			name:     "daily moves on every day",
+			rotation: Rotation{},
+			duties: []dao.Duty{
+				{ID: 1, DutyID: "alice", LastDutyDate: &monday, ShiftStartDate: &monday},
+				{ID: 2, DutyID: "bob"},
+			},
+			date:           tuesday,
+			wantDutyID:     "bob",
+			wantShiftStart: tuesday,
+		},
+		{
+			name:     "weekly keeps the holder during the week",
+			rotation: weekly,
+			duties: []dao.Duty{
+				{ID: 1, DutyID: "alice", LastDutyDate: &tuesday, ShiftStartDate: &monday},
+				{ID: 2, DutyID: "bob"},
+			},
+			date:           thursday,
+			wantDutyID:     "alice",
+			wantShiftStart: monday,
+		},
+		{
+			name:     "weekly hands over on the handover day",
+			rotation: weekly,
+			duties: []dao.Duty{
+				{ID: 1, DutyID: "alice", LastDutyDate: &thursday, ShiftStartDate: &monday},
+				{ID: 2, DutyID: "bob"},
+			},
+			date:           nextMonday,
+			wantDutyID:     "bob",
+			wantShiftStart: nextMonday,
+		},
+		{
+			name:     "weekly hands over when the previous week ended",
+			rotation: weekly,
+			duties: []dao.Duty{
+				{ID: 1, DutyID: "alice", LastDutyDate: &lastFriday, ShiftStartDate: &lastFriday},
+				{ID: 2, DutyID: "bob"},
+			},
+			date:           tuesday,
+			wantDutyID:     "bob",
+			wantShiftStart: tuesday,
+		},
+		{
+			name:     "absent holder hands the rest of the shift over",
+			rotation: weekly,
+			duties: []dao.Duty{
+				{ID: 1, DutyID: "alice", LastDutyDate: &tuesday, ShiftStartDate: &monday},
+				{ID: 2, DutyID: "bob"},
+			},
+			absences:       []dao.Absence{{DutyRecordID: 1, From: thursday, To: thursday}},
+			date:           thursday,
+			wantDutyID:     "bob",
+			wantShiftStart: thursday,
+		},
+		{
+			name:     "today's holder keeps the shift start",
+			rotation: weekly,
+			duties: []dao.Duty{
+				{ID: 1, DutyID: "alice", LastDutyDate: &thursday, ShiftStartDate: &monday},
+				{ID: 2, DutyID: "bob"},
+			},
+			date:           thursday,
+			wantDutyID:     "alice",
+			wantShiftStart: monday,
+		},
+		{
+			name:     "records without shift start use the last duty date",
+			rotation: Rotation{Period: PeriodWorkingDays, ShiftDays: 2},
+			duties: []dao.Duty{
+				{ID: 1, DutyID: "alice", LastDutyDate: &monday},
+				{ID: 2, DutyID: "bob"},
+			},
+			date:           tuesday,
+			wantDutyID:     "alice",
+			wantShiftStart: monday,
+		},
+		{
+			name:     "custom shift ends after its working days",
+			rotation: Rotation{Period: PeriodWorkingDays, ShiftDays: 2},
+			duties: []dao.Duty{
+				{ID: 1, DutyID: "alice", LastDutyDate: &tuesday, ShiftStartDate: &monday},
+				{ID: 2, DutyID: "bob"},
+			},
+			date:           monday.AddDate(0, 0, 2),
+			wantDutyID:     "bob",
+			wantShiftStart: monday.AddDate(0, 0, 2),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			duty, shiftStart := FindShiftDuty(tt.duties, tt.date, tt.absences, tt.rotation)
+			if duty == nil {
+				t.Fatal("expected duty, got nil")
+			}
+			if duty.DutyID != tt.wantDutyID {
+				t.Errorf("expected %s, got %s", tt.wantDutyID, duty.DutyID)
+			}
+			if !shiftStart.Equal(tt.wantShiftStart) {
+				t.Errorf("expected shift start %s, got %s", tt.wantShiftStart.Format("2006-01-02"), shiftStart.Format("2006-01-02"))
+			}
+		})
+	}
+}
+
+func TestProjectSchedule_WeeklyRotation(t *testing.T) {
+	weekdaysOnly := func(date time.Time) bool {
+		return date.Weekday() != time.Saturday && date.Weekday() != time.Sunday
+	}
+	// Wednesday, alice has been on duty since Monday
+	today := time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC)
+	monday := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	tuesday := monday.AddDate(0, 0, 1)
+	duties := []dao.Duty{
+		{ID: 1, DutyID: "alice", LastDutyDate: &tuesday, ShiftStartDate: &monday},
+		{ID: 2, DutyID: "bob"},
+	}
+	rotation := Rotation{Period: PeriodWeekly, HandoverDay: time.Monday, IsWorkingDay: weekdaysOnly}
+
+	schedule := ProjectSchedule(duties, NextWorkingDays(today, 8, weekdaysOnly), nil, nil, rotation)
+	expected := []string{"alice", "alice", "alice", "bob", "bob", "bob", "bob", "bob"}
+	for i, day := range schedule {
+		if day.DutyID != expected[i] {
+			t.Errorf("%s: expected %s, got %s", day.Date.Format("Mon 2006-01-02"), expected[i], day.DutyID)
+		}
+	}
+	if duties[0].ShiftStartDate != &monday || !duties[0].LastDutyDate.Equal(tuesday) {
+		t.Error("expected the projection not to modify the input duties")
+	}
+}
+
+func TestService_GetCurrentDuty_KeepsWeeklyShift(t *testing.T) {
+	repo := dao.NewMemoryRepository()
+	service := NewService(repo)
+	today := service.today()
+	yesterday := today.AddDate(0, 0, -1)
+	// The shift started yesterday on the handover day, so it still runs today
+	service = service.WithRotation(Rotation{Period: PeriodWeekly, HandoverDay: yesterday.Weekday()})
+	repo.AddDuty("alice", nil)
+	repo.AddDuty("bob", &yesterday)
+
+	result, err := service.GetCurrentDuty("actor")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.DutyID != "bob" || !result.IsNewAssignment {
+		t.Fatalf("expected bob to continue the shift with a new daily assignment, got %+v", result)
+	}
+
+	bob, _ := repo.GetDutyByDutyID("bob")
+	if bob.ShiftStartDate == nil || !bob.ShiftStartDate.Equal(yesterday) {
+		t.Errorf("expected the shift to keep starting on %s, got %v", yesterday.Format("2006-01-02"), bob.ShiftStartDate)
+	}
+}
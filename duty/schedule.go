@@ -0,0 +1,144 @@
+package duty
+
+import (
+	"sort"
+	"time"
+
+	"watch_bot/dao"
+)
+
+// Periods of the schedule projection in working days, shared by \schedule and the API
+const (
+	DefaultScheduleDays = 7
+	MaxScheduleDays     = 90
+)
+
+// maxScheduleLookahead bounds the search for working days when the calendar has none
+const maxScheduleLookahead = 366
+
+// ScheduledDay is a recorded or projected duty assignment for one day
+type ScheduledDay struct {
+	Date           time.Time
+	DutyID         string // empty when nobody is available
+	ReplacedDutyID string // set when a swap or \next hands the day over from the rotation's choice
+	Projected      bool   // true when the day comes from the projection rather than the history
+}
+
+// GetSchedule projects the rotation over the next days working days starting today
+func (s *Service) GetSchedule(days int, isWorkingDay func(time.Time) bool) ([]ScheduledDay, error) {
+	dates := NextWorkingDays(s.today(), days, isWorkingDay)
+	if len(dates) == 0 {
+		return nil, nil
+	}
+
+	duties, err := s.repository.GetAllDuties()
+	if err != nil {
+		return nil, err
+	}
+	from, to := dates[0], dates[len(dates)-1]
+	absences, err := s.repository.GetAbsences(from, to)
+	if err != nil {
+		return nil, err
+	}
+	swaps, err := s.repository.GetAcceptedSwaps(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return ProjectSchedule(duties, dates, absences, swaps, s.rotation), nil
+}
+
+// GetDutyDays combines the recorded history of the last pastDays days with the projection
+// of the next futureDays working days
+func (s *Service) GetDutyDays(pastDays, futureDays int, isWorkingDay func(time.Time) bool) ([]ScheduledDay, error) {
+	history, err := s.GetHistory(pastDays)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := s.GetSchedule(futureDays, isWorkingDay)
+	if err != nil {
+		return nil, err
+	}
+	return MergeDutyDays(history, schedule), nil
+}
+
+// MergeDutyDays turns history entries (most recent first) into days, keeping the latest entry of each date,
+// and adds projected days for dates without history. The result is ordered by date.
+func MergeDutyDays(history []dao.HistoryEntry, schedule []ScheduledDay) []ScheduledDay {
+	byDate := make(map[string]ScheduledDay)
+	for _, entry := range history {
+		key := entry.DutyDate.Format("2006-01-02")
+		if _, ok := byDate[key]; ok || entry.Role == dao.RoleSecondary {
+			continue
+		}
+		byDate[key] = ScheduledDay{
+			Date:           entry.DutyDate,
+			DutyID:         entry.DutyID,
+			ReplacedDutyID: entry.ReplacedDutyID,
+		}
+	}
+	for _, day := range schedule {
+		key := day.Date.Format("2006-01-02")
+		if _, ok := byDate[key]; !ok && day.DutyID != "" {
+			byDate[key] = day
+		}
+	}
+
+	days := make([]ScheduledDay, 0, len(byDate))
+	for _, day := range byDate {
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Date.Before(days[j].Date)
+	})
+	return days
+}
+
+// NextWorkingDays returns up to count working days starting from the date of from.
+// A nil isWorkingDay treats every day as a working day.
+func NextWorkingDays(from time.Time, count int, isWorkingDay func(time.Time) bool) []time.Time {
+	var dates []time.Time
+	date := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	for i := 0; len(dates) < count && i < count+maxScheduleLookahead; i++ {
+		if isWorkingDay == nil || isWorkingDay(date) {
+			dates = append(dates, date)
+		}
+		date = date.AddDate(0, 0, 1)
+	}
+	return dates
+}
+
+// ProjectSchedule simulates the rotation over the given working days without touching the database.
+// This is a pure function for easy testing
+func ProjectSchedule(duties []dao.Duty, dates []time.Time, absences []dao.Absence, swaps []dao.Swap, rotation Rotation) []ScheduledDay {
+	simulated := make([]dao.Duty, len(duties))
+	for i, duty := range duties {
+		simulated[i] = duty
+		if duty.LastDutyDate != nil {
+			lastDutyDate := *duty.LastDutyDate
+			simulated[i].LastDutyDate = &lastDutyDate
+		}
+		if duty.ShiftStartDate != nil {
+			shiftStartDate := *duty.ShiftStartDate
+			simulated[i].ShiftStartDate = &shiftStartDate
+		}
+	}
+
+	schedule := make([]ScheduledDay, 0, len(dates))
+	for _, date := range dates {
+		day := ScheduledDay{Date: date, Projected: true}
+		duty, shiftStart := FindShiftDuty(simulated, date, absences, rotation)
+		if duty != nil {
+			assignedDate := date
+			duty.LastDutyDate = &assignedDate
+			duty.ShiftStartDate = &shiftStart
+			onDuty := ApplySwap(simulated, duty, date, swaps)
+			day.DutyID = onDuty.DutyID
+			if onDuty != duty {
+				day.ReplacedDutyID = duty.DutyID
+			}
+		}
+		schedule = append(schedule, day)
+	}
+	return schedule
+}
@@ -0,0 +1,138 @@
+package duty
+
+import (
+	"testing"
+	"time"
+
+	"watch_bot/dao"
+)
+
+func weekdaysOnly(date time.Time) bool {
+	return date.Weekday() != time.Saturday && date.Weekday() != time.Sunday
+}
+
+func TestNextWorkingDays_SkipsDaysOff(t *testing.T) {
+	friday := time.Date(2026, 1, 9, 15, 0, 0, 0, time.UTC)
+
+	dates := NextWorkingDays(friday, 3, weekdaysOnly)
+	expected := []time.Time{
+		time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 1, 13, 0, 0, 0, 0, time.UTC),
+	}
+	if len(dates) != len(expected) {
+		t.Fatalf("expected %d dates, got %v", len(expected), dates)
+	}
+	for i := range expected {
+		if !dates[i].Equal(expected[i]) {
+			t.Errorf("date %d: expected %v, got %v", i, expected[i], dates[i])
+		}
+	}
+}
+
+func TestNextWorkingDays_NoWorkingDays(t *testing.T) {
+	dates := NextWorkingDays(time.Now(), 5, func(time.Time) bool { return false })
+	if len(dates) != 0 {
+		t.Errorf("expected no dates, got %v", dates)
+	}
+}
+
+func TestProjectSchedule_RotatesAlphabetically(t *testing.T) {
+	today := time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC)
+	duties := []dao.Duty{
+		{ID: 1, DutyID: "alice", LastDutyDate: &today},
+		{ID: 2, DutyID: "bob"},
+		{ID: 3, DutyID: "charlie"},
+	}
+	dates := NextWorkingDays(today, 4, weekdaysOnly)
+
+	schedule := ProjectSchedule(duties, dates, nil, nil, Rotation{})
+	expected := []string{"alice", "bob", "charlie", "alice"}
+	for i, dutyID := range expected {
+		if schedule[i].DutyID != dutyID {
+			t.Errorf("day %s: expected %s, got %s", schedule[i].Date.Format("2006-01-02"), dutyID, schedule[i].DutyID)
+		}
+	}
+	if duties[0].LastDutyDate == nil || !duties[0].LastDutyDate.Equal(today) {
+		t.Error("expected projection not to modify the input duties")
+	}
+}
+
+func TestProjectSchedule_AbsencesAndSwaps(t *testing.T) {
+	today := time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC) // Thursday
+	duties := []dao.Duty{
+		{ID: 1, DutyID: "alice", LastDutyDate: &today},
+		{ID: 2, DutyID: "bob"},
+		{ID: 3, DutyID: "charlie"},
+	}
+	friday := today.AddDate(0, 0, 1)
+	monday := today.AddDate(0, 0, 4)
+	absences := []dao.Absence{{DutyRecordID: 2, From: friday, To: friday}}
+	swaps := []dao.Swap{{RequesterRecordID: 1, TargetRecordID: 3, Date: monday, Status: dao.SwapAccepted}}
+
+	schedule := ProjectSchedule(duties, NextWorkingDays(today, 4, weekdaysOnly), absences, swaps, Rotation{})
+
+	expected := []ScheduledDay{
+		{Date: today, DutyID: "alice"},
+		{Date: friday, DutyID: "charlie"},
+		{Date: monday, DutyID: "charlie", ReplacedDutyID: "alice"},
+		{Date: monday.AddDate(0, 0, 1), DutyID: "bob"},
+	}
+	for i := range expected {
+		if schedule[i].DutyID != expected[i].DutyID || schedule[i].ReplacedDutyID != expected[i].ReplacedDutyID || !schedule[i].Date.Equal(expected[i].Date) {
+			t.Errorf("day %d: expected %+v, got %+v", i, expected[i], schedule[i])
+		}
+	}
+}
+
+func TestService_GetSchedule_DoesNotAssign(t *testing.T) {
+	repo := dao.NewMemoryRepository()
+	repo.AddDuty("alice", nil)
+	repo.AddDuty("bob", nil)
+	service := NewService(repo)
+
+	schedule, err := service.GetSchedule(3, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(schedule) != 3 || schedule[0].DutyID != "alice" || schedule[1].DutyID != "bob" || schedule[2].DutyID != "alice" {
+		t.Errorf("unexpected schedule: %+v", schedule)
+	}
+
+	duties, _ := repo.GetAllDuties()
+	for _, duty := range duties {
+		if duty.LastDutyDate != nil {
+			t.Errorf("expected projection not to assign %s", duty.DutyID)
+		}
+	}
+}
+
+func TestMergeDutyDays_PrefersLatestHistory(t *testing.T) {
+	yesterday := time.Date(2026, 1, 7, 0, 0, 0, 0, time.UTC)
+	today := yesterday.AddDate(0, 0, 1)
+	tomorrow := today.AddDate(0, 0, 1)
+	history := []dao.HistoryEntry{
+		{ID: 3, DutyID: "charlie", DutyDate: today, ReplacedDutyID: "bob", Reason: dao.ReasonNext},
+		{ID: 2, DutyID: "bob", DutyDate: today, Reason: dao.ReasonRotation},
+		{ID: 1, DutyID: "alice", DutyDate: yesterday, Reason: dao.ReasonRotation},
+	}
+	schedule := []ScheduledDay{
+		{Date: today, DutyID: "charlie", Projected: true},
+		{Date: tomorrow, DutyID: "alice", Projected: true},
+	}
+
+	days := MergeDutyDays(history, schedule)
+	expected := []ScheduledDay{
+		{Date: yesterday, DutyID: "alice"},
+		{Date: today, DutyID: "charlie", ReplacedDutyID: "bob"},
+		{Date: tomorrow, DutyID: "alice", Projected: true},
+	}
+	if len(days) != len(expected) {
+		t.Fatalf("expected %d days, got %+v", len(expected), days)
+	}
+	for i := range expected {
+		if days[i] != expected[i] {
+			t.Errorf("day %d: expected %+v, got %+v", i, expected[i], days[i])
+		}
+	}
+}
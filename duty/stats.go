@@ -0,0 +1,106 @@
+package duty
+
+import (
+	"sort"
+	"time"
+
+	"watch_bot/dao"
+)
+
+// Periods of the duty statistics in days
+const (
+	DefaultStatsDays = 30
+	MaxStatsDays     = 365
+)
+
+// PersonStats is the duty load of one person over a period
+type PersonStats struct {
+	DutyID string
+	// DutyDays is the number of days the person ended up on primary duty
+	DutyDays int
+	// NextSkips is the number of times \next moved the duty from the person to somebody else
+	NextSkips int
+	// Pages is the number of calls the person received as primary or backup
+	Pages int
+	// OffDayShifts is the number of duty days on days off or unusual days
+	OffDayShifts int
+}
+
+// RecordPage stores that the duty person was called in the given role
+func (s *Service) RecordPage(dutyID, actorUserId, role string) error {
+	record, err := s.repository.GetDutyByDutyID(dutyID)
+	if err != nil {
+		return err
+	}
+	if record == nil {
+		return ErrNotInRotation
+	}
+	return s.repository.RecordPage(dao.Page{
+		DutyRecordID: record.ID,
+		PagedAt:      s.clock.Now(),
+		ActorUserID:  actorUserId,
+		Role:         role,
+	})
+}
+
+// GetStats returns the statistics of everybody in the rotation or in its history over the last days days.
+// isRegularDay tells regular working days from days off and unusual days, nil treats every day as regular.
+func (s *Service) GetStats(days int, isRegularDay func(time.Time) bool) ([]PersonStats, error) {
+	since := s.today().AddDate(0, 0, -(days - 1))
+	duties, err := s.repository.GetAllDuties()
+	if err != nil {
+		return nil, err
+	}
+	history, err := s.repository.GetDutyHistory(since)
+	if err != nil {
+		return nil, err
+	}
+	pages, err := s.repository.GetPages(since)
+	if err != nil {
+		return nil, err
+	}
+	return ComputeStats(duties, history, pages, isRegularDay), nil
+}
+
+// ComputeStats counts duty days, \next skips, pages and off-day shifts per person.
+// A day counts for the person who was on primary duty at its end. The result is ordered by duty ID.
+// This is a pure function for easy testing
+func ComputeStats(duties []dao.Duty, history []dao.HistoryEntry, pages []dao.Page, isRegularDay func(time.Time) bool) []PersonStats {
+	byDutyID := make(map[string]*PersonStats)
+	statsOf := func(dutyID string) *PersonStats {
+		stats, ok := byDutyID[dutyID]
+		if !ok {
+			stats = &PersonStats{DutyID: dutyID}
+			byDutyID[dutyID] = stats
+		}
+		return stats
+	}
+
+	for _, duty := range duties {
+		statsOf(duty.DutyID)
+	}
+	for _, day := range MergeDutyDays(history, nil) {
+		stats := statsOf(day.DutyID)
+		stats.DutyDays++
+		if isRegularDay != nil && !isRegularDay(day.Date) {
+			stats.OffDayShifts++
+		}
+	}
+	for _, entry := range history {
+		if entry.Reason == dao.ReasonNext && entry.Role != dao.RoleSecondary && entry.ReplacedDutyID != "" {
+			statsOf(entry.ReplacedDutyID).NextSkips++
+		}
+	}
+	for _, page := range pages {
+		statsOf(page.DutyID).Pages++
+	}
+
+	result := make([]PersonStats, 0, len(byDutyID))
+	for _, stats := range byDutyID {
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].DutyID < result[j].DutyID
+	})
+	return result
+}
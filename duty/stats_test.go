@@ -0,0 +1,67 @@
+package duty
+
+import (
+	"testing"
+	"time"
+
+	"watch_bot/dao"
+)
+
+func TestComputeStats(t *testing.T) {
+	friday := time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC)
+	saturday := friday.AddDate(0, 0, 1)
+	thursday := friday.AddDate(0, 0, -1)
+	duties := []dao.Duty{{ID: 1, DutyID: "bob"}, {ID: 2, DutyID: "alice"}, {ID: 3, DutyID: "carol"}}
+	// Newest first, as the repository returns it; dave has left the rotation
+	history := []dao.HistoryEntry{
+		{DutyID: "alice", DutyDate: saturday, Reason: dao.ReasonRotation},
+		{DutyID: "bob", DutyDate: saturday, Reason: dao.ReasonRotation, Role: dao.RoleSecondary},
+		{DutyID: "bob", DutyDate: friday, Reason: dao.ReasonNext, ReplacedDutyID: "alice"},
+		{DutyID: "alice", DutyDate: friday, Reason: dao.ReasonRotation},
+		{DutyID: "dave", DutyDate: thursday, Reason: dao.ReasonRotation},
+	}
+	pages := []dao.Page{{DutyID: "bob"}, {DutyID: "bob", Role: dao.RoleSecondary}, {DutyID: "alice"}}
+	isRegularDay := func(date time.Time) bool {
+		return date.Weekday() != time.Saturday && date.Weekday() != time.Sunday
+	}
+
+	stats := ComputeStats(duties, history, pages, isRegularDay)
+	expected := []PersonStats{
+		{DutyID: "alice", DutyDays: 1, NextSkips: 1, Pages: 1, OffDayShifts: 1},
+		{DutyID: "bob", DutyDays: 1, Pages: 2},
+		{DutyID: "carol"},
+		{DutyID: "dave", DutyDays: 1},
+	}
+	if len(stats) != len(expected) {
+		t.Fatalf("expected %d people, got %+v", len(expected), stats)
+	}
+	for i := range expected {
+		if stats[i] != expected[i] {
+			t.Errorf("person %d: expected %+v, got %+v", i, expected[i], stats[i])
+		}
+	}
+}
+
+func TestService_GetStats_CountsRecordedPages(t *testing.T) {
+	repo := dao.NewMemoryRepository()
+	repo.AddDuty("alice", nil)
+	service := NewService(repo)
+
+	if _, err := service.GetCurrentDuty("caller"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := service.RecordPage("alice", "caller", dao.RolePrimary); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := service.RecordPage("nobody", "caller", dao.RolePrimary); err != ErrNotInRotation {
+		t.Errorf("expected ErrNotInRotation for an unknown person, got %v", err)
+	}
+
+	stats, err := service.GetStats(DefaultStatsDays, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(stats) != 1 || stats[0] != (PersonStats{DutyID: "alice", DutyDays: 1, Pages: 1}) {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
@@ -0,0 +1,144 @@
+package duty
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"watch_bot/dao"
+)
+
+// RotationStrategy decides in which order people take over the duty
+type RotationStrategy interface {
+	// Order returns indexes of duties in the order they are offered the next shift on date.
+	// last is the index of the person who had the previous shift, or -1 when nobody has been on duty yet;
+	// it is offered last, if at all.
+	Order(duties []dao.Duty, last int, date time.Time) []int
+}
+
+// Names of the rotation strategies accepted by ParseRotationStrategy
+const (
+	StrategyAlphabetical        = "alphabetical"
+	StrategyPosition            = "position"
+	StrategyLeastRecentlyServed = "least-recently-served"
+	StrategyWeighted            = "weighted"
+)
+
+// ParseRotationStrategy returns the strategy with the given name, an empty name is alphabetical
+func ParseRotationStrategy(name string) (RotationStrategy, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", StrategyAlphabetical:
+		return AlphabeticalStrategy{}, nil
+	case StrategyPosition:
+		return PositionStrategy{}, nil
+	case StrategyLeastRecentlyServed:
+		return LeastRecentlyServedStrategy{}, nil
+	case StrategyWeighted:
+		return WeightedStrategy{}, nil
+	}
+	return nil, fmt.Errorf("unknown rotation strategy %q", name)
+}
+
+// AlphabeticalStrategy walks the people in the order of their duty IDs
+type AlphabeticalStrategy struct{}
+
+// Order implements RotationStrategy
+func (AlphabeticalStrategy) Order(duties []dao.Duty, last int, _ time.Time) []int {
+	return cycleAfter(sortedIndexes(duties, func(a, b dao.Duty) bool {
+		return a.DutyID < b.DutyID
+	}), last)
+}
+
+// PositionStrategy walks the people in the order of their position and then of joining the rotation,
+// so a newcomer does not reshuffle everybody else
+type PositionStrategy struct{}
+
+// Order implements RotationStrategy
+func (PositionStrategy) Order(duties []dao.Duty, last int, _ time.Time) []int {
+	return cycleAfter(sortedIndexes(duties, func(a, b dao.Duty) bool {
+		if a.Position != b.Position {
+			return a.Position < b.Position
+		}
+		return a.ID < b.ID
+	}), last)
+}
+
+// LeastRecentlyServedStrategy offers the duty to whoever has waited the longest since their last duty.
+// People who have never been on duty go first.
+type LeastRecentlyServedStrategy struct{}
+
+// Order implements RotationStrategy
+func (LeastRecentlyServedStrategy) Order(duties []dao.Duty, last int, date time.Time) []int {
+	return withLast(sortedIndexes(duties, func(a, b dao.Duty) bool {
+		aIdle, bIdle := idleDays(a, date), idleDays(b, date)
+		if aIdle != bIdle {
+			return aIdle > bIdle
+		}
+		return a.DutyID < b.DutyID
+	}), last)
+}
+
+// WeightedStrategy is the least-recently-served rotation where the waiting time is multiplied
+// by the weight of the person. A part-time member with weight 0.5 waits twice as long as the others
+// and so takes about half as many shifts. Weights that are not positive count as 1.
+type WeightedStrategy struct{}
+
+// Order implements RotationStrategy
+func (WeightedStrategy) Order(duties []dao.Duty, last int, date time.Time) []int {
+	return withLast(sortedIndexes(duties, func(a, b dao.Duty) bool {
+		aScore, bScore := idleDays(a, date)*weightOf(a), idleDays(b, date)*weightOf(b)
+		if aScore != bScore {
+			return aScore > bScore
+		}
+		return a.DutyID < b.DutyID
+	}), last)
+}
+
+// sortedIndexes returns indexes of duties sorted with less
+func sortedIndexes(duties []dao.Duty, less func(a, b dao.Duty) bool) []int {
+	indexes := make([]int, len(duties))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return less(duties[indexes[i]], duties[indexes[j]])
+	})
+	return indexes
+}
+
+// cycleAfter rotates the order so that it starts right after last and ends with it
+func cycleAfter(order []int, last int) []int {
+	for i, index := range order {
+		if index == last {
+			return append(order[i+1:], order[:i+1]...)
+		}
+	}
+	return order
+}
+
+// withLast moves last to the end of the order
+func withLast(order []int, last int) []int {
+	for i, index := range order {
+		if index == last {
+			return append(append(order[:i:i], order[i+1:]...), last)
+		}
+	}
+	return order
+}
+
+// idleDays returns the number of days since the last duty, infinity when there was none
+func idleDays(duty dao.Duty, date time.Time) float64 {
+	if duty.LastDutyDate == nil {
+		return math.Inf(1)
+	}
+	return dayOf(date).Sub(dayOf(*duty.LastDutyDate)).Hours() / 24
+}
+
+func weightOf(duty dao.Duty) float64 {
+	if duty.Weight <= 0 {
+		return 1
+	}
+	return duty.Weight
+}
@@ -0,0 +1,124 @@
+package duty
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"watch_bot/dao"
+)
+
+func TestParseRotationStrategy(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    RotationStrategy
+		wantErr bool
+	}{
+		{"", AlphabeticalStrategy{}, false},
+		{"alphabetical", AlphabeticalStrategy{}, false},
+		{"Position", PositionStrategy{}, false},
+		{"least-recently-served", LeastRecentlyServedStrategy{}, false},
+		{"weighted", WeightedStrategy{}, false},
+		{"random", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRotationStrategy(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRotationStrategy(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRotationStrategy(%q) = %#v, want %#v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRotationStrategies_Order(t *testing.T) {
+	today := time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) *time.Time {
+		date := today.AddDate(0, 0, -days)
+		return &date
+	}
+	// aaron joined last, carol has the lowest position
+	duties := []dao.Duty{
+		{ID: 1, DutyID: "bob", LastDutyDate: daysAgo(1), Position: 2, Weight: 1},
+		{ID: 2, DutyID: "carol", LastDutyDate: daysAgo(4), Position: 1, Weight: 1},
+		{ID: 3, DutyID: "dave", LastDutyDate: daysAgo(3), Position: 2, Weight: 0.5},
+		{ID: 4, DutyID: "aaron", Position: 3, Weight: 1},
+	}
+
+	tests := []struct {
+		name     string
+		strategy RotationStrategy
+		last     int
+		want     []string
+	}{
+		{"alphabetical after bob", AlphabeticalStrategy{}, 0, []string{"carol", "dave", "aaron", "bob"}},
+		{"alphabetical from the start", AlphabeticalStrategy{}, -1, []string{"aaron", "bob", "carol", "dave"}},
+		{"position after bob", PositionStrategy{}, 0, []string{"dave", "aaron", "carol", "bob"}},
+		{"position keeps join order for equal positions", PositionStrategy{}, 1, []string{"bob", "dave", "aaron", "carol"}},
+		{"least recently served", LeastRecentlyServedStrategy{}, 0, []string{"aaron", "carol", "dave", "bob"}},
+		{"weighted halves the waiting of part-timers", WeightedStrategy{}, 0, []string{"aaron", "carol", "dave", "bob"}},
+		{"weighted moves last to the end", WeightedStrategy{}, 1, []string{"aaron", "dave", "bob", "carol"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, i := range tt.strategy.Order(duties, tt.last, today) {
+				got = append(got, duties[i].DutyID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWeightedStrategy_PartTimerServesLess(t *testing.T) {
+	duties := []dao.Duty{
+		{ID: 1, DutyID: "alice", Weight: 1},
+		{ID: 2, DutyID: "bob", Weight: 1},
+		{ID: 3, DutyID: "carol", Weight: 0.5},
+	}
+	dates := NextWorkingDays(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), 50, nil)
+
+	served := make(map[string]int)
+	for _, day := range ProjectSchedule(duties, dates, nil, nil, Rotation{Strategy: WeightedStrategy{}}) {
+		served[day.DutyID]++
+	}
+	if served["carol"] >= served["alice"] || served["carol"] >= served["bob"] {
+		t.Errorf("expected the part-timer to serve less, got %v", served)
+	}
+	if served["carol"] == 0 {
+		t.Errorf("expected the part-timer to serve at all, got %v", served)
+	}
+}
+
+func TestFindShiftDuty_UsesStrategy(t *testing.T) {
+	today := time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC)
+	yesterday := today.AddDate(0, 0, -1)
+	duties := []dao.Duty{
+		{ID: 1, DutyID: "bob", LastDutyDate: &yesterday, Position: 1},
+		{ID: 2, DutyID: "carol", Position: 2},
+		{ID: 3, DutyID: "aaron", Position: 3},
+	}
+
+	alphabetical, _ := FindShiftDuty(slices.Clone(duties), today, nil, Rotation{})
+	if alphabetical.DutyID != "carol" {
+		t.Errorf("expected carol after bob alphabetically, got %s", alphabetical.DutyID)
+	}
+	// A newcomer with a later position does not jump the queue
+	byPosition, _ := FindShiftDuty(slices.Clone(duties), today, nil, Rotation{Strategy: PositionStrategy{}})
+	if byPosition.DutyID != "carol" {
+		t.Errorf("expected carol after bob by position, got %s", byPosition.DutyID)
+	}
+	// Absent people are skipped in the order of the strategy
+	absences := []dao.Absence{{DutyRecordID: 2, From: today, To: today}}
+	skipped, _ := FindShiftDuty(slices.Clone(duties), today, absences, Rotation{Strategy: PositionStrategy{}})
+	if skipped.DutyID != "aaron" {
+		t.Errorf("expected aaron when carol is absent, got %s", skipped.DutyID)
+	}
+}
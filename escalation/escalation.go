@@ -0,0 +1,185 @@
+package escalation
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+	"watch_bot/bots"
+
+	botgolang "github.com/mail-ru-im/bot-golang"
+)
+
+// Config contains configuration for the acknowledgement tracker
+type Config struct {
+	// Timeout is how long each person has to acknowledge before the call is escalated
+	Timeout time.Duration
+	// EscalationUserIds is a fixed escalation chain; when empty the call goes along the rotation
+	EscalationUserIds []string
+	// NextInRotation returns the next available person after afterDutyID who is not in excluded
+	NextInRotation func(afterDutyID string, excluded []string) (string, error)
+	MessagesChan   chan bots.Message
+	SupportChatId  string
+}
+
+// lastCallID keeps call IDs unique between the trackers of different teams,
+// so \ack <id> always refers to a single call
+var lastCallID atomic.Int64
+
+// timer is the part of *time.Timer used by the tracker
+type timer interface {
+	Stop() bool
+}
+
+// call is a duty call waiting for acknowledgement
+type call struct {
+	id       int64
+	details  string
+	notified []string // everybody who was asked to acknowledge, the last one is asked now
+	timer    timer
+}
+
+// Tracker waits for the duty person to acknowledge a call and escalates it when nobody reacts in time
+type Tracker struct {
+	config    Config
+	afterFunc func(time.Duration, func()) timer
+
+	mu    sync.Mutex
+	calls map[int64]*call
+}
+
+// NewTracker creates a new Tracker
+func NewTracker(config Config) *Tracker {
+	return &Tracker{
+		config: config,
+		afterFunc: func(d time.Duration, f func()) timer {
+			return time.AfterFunc(d, f)
+		},
+		calls: make(map[int64]*call),
+	}
+}
+
+// AckButton returns the inline button that acknowledges the call
+func AckButton(callID int64) bots.Button {
+	return bots.Button{Text: "Acknowledge", Command: fmt.Sprintf("\\ack %d", callID)}
+}
+
+// Start begins waiting for dutyID to acknowledge a call and returns the call ID.
+// A call that dutyID has not acknowledged yet is reused instead of starting a second escalation.
+func (t *Tracker) Start(dutyID, details string) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, pending := range t.calls {
+		if pending.notified[0] == dutyID {
+			return pending.id
+		}
+	}
+
+	pending := &call{
+		id:       lastCallID.Add(1),
+		details:  details,
+		notified: []string{dutyID},
+	}
+	t.calls[pending.id] = pending
+	pending.timer = t.afterFunc(t.config.Timeout, func() { t.escalate(pending.id) })
+
+	t.notifySupport(fmt.Sprintf("⏳ Waiting for @[%s] to acknowledge the duty call within %s", dutyID, t.config.Timeout))
+	return pending.id
+}
+
+// Acknowledge stops the escalation of calls userId was asked to acknowledge.
+// When callID is 0 every such call is acknowledged. It returns the number of acknowledged calls.
+func (t *Tracker) Acknowledge(userId string, callID int64) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	acknowledged := 0
+	for id, pending := range t.calls {
+		if (callID != 0 && id != callID) || !slices.Contains(pending.notified, userId) {
+			continue
+		}
+		pending.timer.Stop()
+		delete(t.calls, id)
+		acknowledged++
+	}
+	if acknowledged > 0 {
+		t.notifySupport(fmt.Sprintf("✅ @[%s] acknowledged the duty call", userId))
+	}
+	return acknowledged
+}
+
+// escalate hands an unacknowledged call to the next person in the escalation chain
+func (t *Tracker) escalate(callID int64) {
+	t.mu.Lock()
+	pending, ok := t.calls[callID]
+	if !ok {
+		t.mu.Unlock()
+		return
+	}
+	notified := slices.Clone(pending.notified)
+	t.mu.Unlock()
+
+	next, err := t.nextTarget(notified)
+	if err != nil {
+		log.Printf("failed to find escalation target for call %d: %v", callID, err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.calls[callID]; !ok {
+		// Acknowledged while the next target was being looked up
+		return
+	}
+
+	last := notified[len(notified)-1]
+	if next == "" {
+		delete(t.calls, callID)
+		t.notifySupport(fmt.Sprintf("❌ Nobody acknowledged the duty call, @[%s] was the last one asked", last))
+		return
+	}
+
+	pending.notified = append(pending.notified, next)
+	pending.timer = t.afterFunc(t.config.Timeout, func() { t.escalate(callID) })
+
+	text := fmt.Sprintf("⬆️ The duty call was escalated to you because @[%s] did not acknowledge it", last)
+	if pending.details != "" {
+		text += "\n\n" + pending.details
+	}
+	bots.SendNonBlocking(t.config.MessagesChan, bots.Message{
+		ChatId:    next,
+		Text:      text,
+		ParseMode: string(botgolang.ParseModeHTML),
+		Buttons:   []bots.Button{AckButton(callID)},
+	})
+	t.notifySupport(fmt.Sprintf("⬆️ @[%s] did not acknowledge within %s, escalating to @[%s]", last, t.config.Timeout, next))
+}
+
+// nextTarget returns the first person of the escalation chain who was not asked yet
+func (t *Tracker) nextTarget(notified []string) (string, error) {
+	if len(t.config.EscalationUserIds) > 0 {
+		for _, userId := range t.config.EscalationUserIds {
+			if !slices.Contains(notified, userId) {
+				return userId, nil
+			}
+		}
+		return "", nil
+	}
+	if t.config.NextInRotation == nil {
+		return "", nil
+	}
+	return t.config.NextInRotation(notified[len(notified)-1], notified)
+}
+
+func (t *Tracker) notifySupport(text string) {
+	if t.config.SupportChatId == "" {
+		return
+	}
+	bots.SendNonBlocking(t.config.MessagesChan, bots.Message{
+		ChatId:    t.config.SupportChatId,
+		Text:      text,
+		ParseMode: string(botgolang.ParseModeHTML),
+	})
+}
@@ -0,0 +1,160 @@
+package escalation
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"watch_bot/bots"
+)
+
+// fakeTimers captures scheduled escalations so that tests can fire them explicitly
+type fakeTimers struct {
+	pending []*fakeTimer
+}
+
+type fakeTimer struct {
+	f       func()
+	stopped bool
+}
+
+func (f *fakeTimer) Stop() bool {
+	wasActive := !f.stopped
+	f.stopped = true
+	return wasActive
+}
+
+func (f *fakeTimers) afterFunc(_ time.Duration, fn func()) timer {
+	t := &fakeTimer{f: fn}
+	f.pending = append(f.pending, t)
+	return t
+}
+
+// fireLast runs the most recently scheduled escalation
+func (f *fakeTimers) fireLast() {
+	f.pending[len(f.pending)-1].f()
+}
+
+func newTestTracker(config Config) (*Tracker, *fakeTimers, chan bots.Message) {
+	messagesChan := make(chan bots.Message, 20)
+	config.MessagesChan = messagesChan
+	config.SupportChatId = "support"
+	config.Timeout = 5 * time.Minute
+	tracker := NewTracker(config)
+	timers := &fakeTimers{}
+	tracker.afterFunc = timers.afterFunc
+	return tracker, timers, messagesChan
+}
+
+func drain(messagesChan chan bots.Message) []bots.Message {
+	var messages []bots.Message
+	for {
+		select {
+		case message := <-messagesChan:
+			messages = append(messages, message)
+		default:
+			return messages
+		}
+	}
+}
+
+func TestTracker_AcknowledgeStopsEscalation(t *testing.T) {
+	tracker, timers, messagesChan := newTestTracker(Config{EscalationUserIds: []string{"lead"}})
+
+	id := tracker.Start("alice", "")
+	if again := tracker.Start("alice", ""); again != id {
+		t.Errorf("expected pending call %d to be reused, got %d", id, again)
+	}
+	if tracker.Acknowledge("bob", 0) != 0 {
+		t.Error("expected bob not to be able to acknowledge alice's call")
+	}
+	if tracker.Acknowledge("alice", id) != 1 {
+		t.Fatal("expected alice to acknowledge the call")
+	}
+	if !timers.pending[0].stopped {
+		t.Error("expected escalation timer to be stopped")
+	}
+
+	messages := drain(messagesChan)
+	if len(messages) != 2 || !strings.Contains(messages[1].Text, "@[alice] acknowledged") {
+		t.Fatalf("unexpected support messages %+v", messages)
+	}
+	if tracker.Acknowledge("alice", 0) != 0 {
+		t.Error("expected nothing left to acknowledge")
+	}
+}
+
+func TestTracker_EscalatesAlongConfiguredList(t *testing.T) {
+	tracker, timers, messagesChan := newTestTracker(Config{EscalationUserIds: []string{"alice", "lead", "cto"}})
+
+	id := tracker.Start("alice", "<b>Deploy failed</b>")
+	drain(messagesChan)
+
+	timers.fireLast()
+	messages := drain(messagesChan)
+	if len(messages) != 2 {
+		t.Fatalf("expected escalation and support messages, got %+v", messages)
+	}
+	if messages[0].ChatId != "lead" || !strings.Contains(messages[0].Text, "Deploy failed") {
+		t.Errorf("expected escalation to lead with details, got %+v", messages[0])
+	}
+	if len(messages[0].Buttons) != 1 || messages[0].Buttons[0].Command != AckButton(id).Command {
+		t.Errorf("expected acknowledge button, got %+v", messages[0].Buttons)
+	}
+	if messages[1].ChatId != "support" || !strings.Contains(messages[1].Text, "escalating to @[lead]") {
+		t.Errorf("unexpected support message %+v", messages[1])
+	}
+
+	timers.fireLast()
+	if messages := drain(messagesChan); messages[0].ChatId != "cto" {
+		t.Errorf("expected escalation to cto, got %+v", messages[0])
+	}
+
+	// Anybody asked so far may acknowledge
+	if tracker.Acknowledge("lead", id) != 1 {
+		t.Fatal("expected lead to acknowledge the escalated call")
+	}
+}
+
+func TestTracker_EscalatesAlongRotationUntilNobodyLeft(t *testing.T) {
+	rotation := []string{"alice", "bob"}
+	tracker, timers, messagesChan := newTestTracker(Config{
+		NextInRotation: func(afterDutyID string, excluded []string) (string, error) {
+			for _, dutyID := range rotation {
+				if dutyID != afterDutyID && !slices.Contains(excluded, dutyID) {
+					return dutyID, nil
+				}
+			}
+			return "", nil
+		},
+	})
+
+	tracker.Start("alice", "")
+	timers.fireLast()
+	if messages := drain(messagesChan); messages[1].ChatId != "bob" {
+		t.Fatalf("expected escalation to bob, got %+v", messages)
+	}
+
+	timers.fireLast()
+	messages := drain(messagesChan)
+	if len(messages) != 1 || !strings.Contains(messages[0].Text, "Nobody acknowledged") {
+		t.Fatalf("expected final support message, got %+v", messages)
+	}
+	if tracker.Acknowledge("bob", 0) != 0 {
+		t.Error("expected the call to be closed after the chain was exhausted")
+	}
+}
+
+func TestTracker_CallIDsAreUniqueAcrossTrackers(t *testing.T) {
+	first, _, _ := newTestTracker(Config{})
+	second, _, _ := newTestTracker(Config{})
+
+	firstID := first.Start("alice", "")
+	secondID := second.Start("bob", "")
+	if firstID == secondID {
+		t.Fatalf("expected different call IDs, got %d twice", firstID)
+	}
+	if second.Acknowledge("bob", firstID) != 0 {
+		t.Error("expected the call of another tracker to be ignored")
+	}
+}
@@ -0,0 +1,270 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405Z"
+	maxLineOctets  = 75
+)
+
+// Event is an all-day calendar event. End is exclusive as required by RFC 5545.
+type Event struct {
+	UID         string
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+}
+
+// WriteCalendar writes the events as an RFC 5545 VCALENDAR document
+func WriteCalendar(w io.Writer, name string, events []Event) error {
+	writer := bufio.NewWriter(w)
+	stamp := time.Now().UTC().Format(dateTimeLayout)
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//watch_bot//duty calendar//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:" + escapeText(name),
+	}
+	for _, event := range events {
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+escapeText(event.UID),
+			"DTSTAMP:"+stamp,
+			"DTSTART;VALUE=DATE:"+event.Start.Format(dateLayout),
+			"DTEND;VALUE=DATE:"+event.End.Format(dateLayout),
+			"SUMMARY:"+escapeText(event.Summary),
+		)
+		if event.Description != "" {
+			lines = append(lines, "DESCRIPTION:"+escapeText(event.Description))
+		}
+		lines = append(lines, "TRANSP:TRANSPARENT", "END:VEVENT")
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := writer.WriteString(foldLine(line)); err != nil {
+			return fmt.Errorf("failed to write calendar: %w", err)
+		}
+	}
+	return writer.Flush()
+}
+
+// Parse reads the events of an RFC 5545 document as all-day events. Date-time bounds keep their date
+// as written, an event without an end lasts one day, and cancelled events as well as the components
+// nested in events, such as alarms, are skipped. Recurrence rules are not expanded.
+func Parse(r io.Reader) ([]Event, error) {
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	var event *Event
+	var duration time.Duration
+	cancelled := false
+	// depth counts the components opened inside the current event
+	depth := 0
+	for _, line := range lines {
+		name, params, value, err := parseContentLine(line)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT") && event == nil:
+			event, duration, cancelled, depth = &Event{}, 0, false, 0
+		case event == nil:
+			continue
+		case name == "BEGIN":
+			depth++
+		case name == "END" && depth > 0:
+			depth--
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if event.Start.IsZero() {
+				return nil, fmt.Errorf("event %q has no DTSTART", event.UID)
+			}
+			if event.End.IsZero() && duration > 0 {
+				event.End = event.Start.Add(duration)
+			}
+			if !event.End.After(event.Start) {
+				event.End = event.Start.AddDate(0, 0, 1)
+			}
+			if !cancelled {
+				events = append(events, *event)
+			}
+			event = nil
+		case depth > 0:
+			continue
+		case name == "UID":
+			event.UID = unescapeText(value)
+		case name == "SUMMARY":
+			event.Summary = unescapeText(value)
+		case name == "DESCRIPTION":
+			event.Description = unescapeText(value)
+		case name == "STATUS":
+			cancelled = strings.EqualFold(value, "CANCELLED")
+		case name == "DTSTART", name == "DTEND":
+			date, err := parseDate(value, params)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s of event %q: %w", name, event.UID, err)
+			}
+			if name == "DTSTART" {
+				event.Start = date
+			} else {
+				event.End = date
+			}
+		case name == "DURATION":
+			if duration, err = parseDuration(value); err != nil {
+				return nil, fmt.Errorf("invalid DURATION of event %q: %w", event.UID, err)
+			}
+		}
+	}
+	if event != nil {
+		return nil, errors.New("calendar ends inside an event")
+	}
+	return events, nil
+}
+
+// unfoldLines reads the content lines, joining the continuation lines that start with a space or a tab
+func unfoldLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+	return lines, nil
+}
+
+// parseContentLine splits "NAME;PARAM=VALUE:value" into the upper-case name, the parameters and the value.
+// Colons inside quoted parameter values do not end the parameters.
+func parseContentLine(line string) (string, map[string]string, string, error) {
+	quoted := false
+	for i, r := range line {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ':' && !quoted:
+			parts := strings.Split(line[:i], ";")
+			params := make(map[string]string, len(parts)-1)
+			for _, param := range parts[1:] {
+				key, value, _ := strings.Cut(param, "=")
+				params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+			}
+			return strings.ToUpper(parts[0]), params, line[i+1:], nil
+		}
+	}
+	return "", nil, "", fmt.Errorf("invalid content line %q", line)
+}
+
+// parseDate returns the date of a DATE or DATE-TIME value as midnight UTC
+func parseDate(value string, params map[string]string) (time.Time, error) {
+	if params["VALUE"] != "DATE" && len(value) > len(dateLayout) && value[len(dateLayout)] == 'T' {
+		value = value[:len(dateLayout)]
+	}
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected YYYYMMDD, got %q", value)
+	}
+	return date, nil
+}
+
+// parseDuration parses the whole days and weeks of a DURATION value such as "P1D" or "P2W",
+// shorter durations keep the event within one day
+func parseDuration(value string) (time.Duration, error) {
+	rest, found := strings.CutPrefix(strings.TrimPrefix(value, "+"), "P")
+	if !found {
+		return 0, fmt.Errorf("unsupported duration %q", value)
+	}
+	days := 0
+	for rest != "" && rest[0] != 'T' {
+		i := strings.IndexAny(rest, "DW")
+		if i < 1 {
+			return 0, fmt.Errorf("unsupported duration %q", value)
+		}
+		n, err := strconv.Atoi(rest[:i])
+		if err != nil {
+			return 0, fmt.Errorf("unsupported duration %q", value)
+		}
+		if rest[i] == 'W' {
+			n *= 7
+		}
+		days += n
+		rest = rest[i+1:]
+	}
+	return time.Duration(days) * 24 * time.Hour, nil
+}
+
+// escapeText escapes a TEXT property value
+func escapeText(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return replacer.Replace(value)
+}
+
+// unescapeText reverses escapeText
+func unescapeText(value string) string {
+	var sb strings.Builder
+	escaped := false
+	for _, r := range value {
+		switch {
+		case escaped && (r == 'n' || r == 'N'):
+			sb.WriteRune('\n')
+		case escaped:
+			sb.WriteRune(r)
+		case r == '\\':
+			escaped = true
+			continue
+		default:
+			sb.WriteRune(r)
+		}
+		escaped = false
+	}
+	return sb.String()
+}
+
+// foldLine splits a content line into CRLF-terminated lines of at most 75 octets
+// without breaking multi-byte characters
+func foldLine(line string) string {
+	var sb strings.Builder
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		sb.WriteString(line[:cut])
+		sb.WriteString("\r\n ")
+		line = line[cut:]
+		// continuation lines start with a space that counts towards the limit
+		limit = maxLineOctets - 1
+	}
+	sb.WriteString(line)
+	sb.WriteString("\r\n")
+	return sb.String()
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
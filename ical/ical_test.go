@@ -0,0 +1,145 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteCalendar(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteCalendar(&buf, "Duty", []Event{{
+		UID:         "2026-01-08-alice@watch_bot",
+		Start:       time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC),
+		End:         time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC),
+		Summary:     "Duty: alice",
+		Description: "swap; replaces bob, carol",
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	output := buf.String()
+	for _, expected := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"X-WR-CALNAME:Duty\r\n",
+		"DTSTART;VALUE=DATE:20260108\r\n",
+		"DTEND;VALUE=DATE:20260109\r\n",
+		"SUMMARY:Duty: alice\r\n",
+		`DESCRIPTION:swap\; replaces bob\, carol` + "\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+	if strings.Count(output, "BEGIN:VEVENT") != 1 {
+		t.Errorf("expected exactly one event, got:\n%s", output)
+	}
+}
+
+func TestFoldLine(t *testing.T) {
+	line := "SUMMARY:" + strings.Repeat("дежурство ", 20)
+	folded := foldLine(line)
+
+	for _, part := range strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n") {
+		if len(part) > maxLineOctets {
+			t.Errorf("line exceeds %d octets: %d", maxLineOctets, len(part))
+		}
+	}
+	unfolded := strings.ReplaceAll(strings.TrimSuffix(folded, "\r\n"), "\r\n ", "")
+	if unfolded != line {
+		t.Errorf("unfolded line differs from the original:\n%q\n%q", unfolded, line)
+	}
+}
+
+func TestParse(t *testing.T) {
+	document := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"UID:new-year@example.com",
+		"DTSTART;VALUE=DATE:20260101",
+		"DTEND;VALUE=DATE:20260103",
+		"SUMMARY:New Year\\, the first",
+		"  days",
+		"BEGIN:VALARM",
+		"DESCRIPTION:reminder",
+		"END:VALARM",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:womens-day@example.com",
+		`DTSTART;TZID="Europe/Moscow":20260309T000000`,
+		"DESCRIPTION:moved from\\nSunday",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:cancelled@example.com",
+		"DTSTART;VALUE=DATE:20260504",
+		"STATUS:CANCELLED",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:may@example.com",
+		"DTSTART;VALUE=DATE:20260511",
+		"DURATION:P1W",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	events, err := Parse(strings.NewReader(document))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("expected 3 events, got %+v", events)
+	}
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2026, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	expected := []Event{
+		{UID: "new-year@example.com", Start: date(1, 1), End: date(1, 3), Summary: "New Year, the first days"},
+		{UID: "womens-day@example.com", Start: date(3, 9), End: date(3, 10), Description: "moved from\nSunday"},
+		{UID: "may@example.com", Start: date(5, 11), End: date(5, 18)},
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Errorf("expected event %d to be %+v, got %+v", i, expected[i], events[i])
+		}
+	}
+}
+
+func TestParseReadsWrittenCalendar(t *testing.T) {
+	written := Event{
+		UID:         "2026-01-08-alice@watch_bot",
+		Start:       time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC),
+		End:         time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC),
+		Summary:     "Duty: alice",
+		Description: strings.Repeat("swap; replaces bob, carol\\", 5),
+	}
+	var buf bytes.Buffer
+	if err := WriteCalendar(&buf, "Duty", []Event{written}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	events, err := Parse(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 1 || events[0] != written {
+		t.Errorf("expected %+v, got %+v", written, events)
+	}
+}
+
+func TestParseRejectsInvalidEvents(t *testing.T) {
+	for _, document := range []string{
+		"BEGIN:VEVENT\nSUMMARY:no start\nEND:VEVENT",
+		"BEGIN:VEVENT\nDTSTART;VALUE=DATE:2026-01-01\nEND:VEVENT",
+		"BEGIN:VEVENT\nDTSTART;VALUE=DATE:20260101\nDURATION:1D\nEND:VEVENT",
+		"BEGIN:VEVENT\nDTSTART;VALUE=DATE:20260101",
+		"BEGIN:VEVENT\nnot a content line\nEND:VEVENT",
+	} {
+		if _, err := Parse(strings.NewReader(document)); err == nil {
+			t.Errorf("expected an error for %q", document)
+		}
+	}
+}
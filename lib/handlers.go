@@ -1,6 +1,8 @@
 package lib
 
 import (
+	"encoding/json"
+	"log"
 	"net/http"
 	"sync/atomic"
 
@@ -11,13 +13,31 @@ func Healthz(w http.ResponseWriter, _ *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
-func Readyz(isReady *atomic.Value) http.HandlerFunc {
+// ReadinessDetail returns the state of a background job to report on /ready under name.
+// It does not affect the readiness itself.
+type ReadinessDetail func() (name string, state any)
+
+func Readyz(isReady *atomic.Value, details ...ReadinessDetail) http.HandlerFunc {
 	return func(w http.ResponseWriter, _ *http.Request) {
 		if isReady == nil || !isReady.Load().(bool) {
 			http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
 			return
 		}
+		if len(details) == 0 {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		states := make(map[string]any, len(details))
+		for _, detail := range details {
+			name, state := detail()
+			states[name] = state
+		}
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(states); err != nil {
+			log.Printf("failed to write readiness details: %v", err)
+		}
 	}
 }
 
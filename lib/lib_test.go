@@ -1,7 +1,10 @@
 package lib
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"sync/atomic"
 	"testing"
 )
 
@@ -30,3 +33,27 @@ func TestGetEnvVariableValueWithDefault(t *testing.T) {
 		}
 	}
 }
+
+func TestReadyz_Details(t *testing.T) {
+	isReady := &atomic.Value{}
+	isReady.Store(true)
+	handler := Readyz(isReady, func() (string, any) {
+		return "calendar", map[string]string{"error": "db down"}
+	})
+
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if recorder.Code != http.StatusOK {
+		t.Errorf("expected details not to affect readiness, got status %d", recorder.Code)
+	}
+	if body := recorder.Body.String(); body != "{\"calendar\":{\"error\":\"db down\"}}\n" {
+		t.Errorf("unexpected body %q", body)
+	}
+
+	isReady.Store(false)
+	recorder = httptest.NewRecorder()
+	handler(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503 during shutdown, got %d", recorder.Code)
+	}
+}
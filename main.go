@@ -12,18 +12,35 @@ import (
 	"sync/atomic"
 	"syscall"
 	"time"
+	// embeds the time zone database, so CALENDAR_TIMEZONE does not depend on the zones installed in the image
+	_ "time/tzdata"
+	"watch_bot/api"
 	"watch_bot/bots"
 	"watch_bot/bots/commands"
 	"watch_bot/dao"
+	"watch_bot/duty"
+	"watch_bot/escalation"
 	"watch_bot/lib"
+	"watch_bot/metrics"
 	"watch_bot/working_calendar"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"gopkg.in/Graylog2/go-gelf.v1/gelf"
 )
 
+// Range of the iCalendar feeds: recorded history and projected working days
+const (
+	calendarPastDays   = 90
+	calendarFutureDays = 60
+)
+
+// statsMetricsDays is the period of the duty statistics gauges on /metrics
+const statsMetricsDays = duty.DefaultStatsDays
+
 func main() {
 	graylogAddr := os.Getenv("GRAYLOG_ADDR")
 	// graylog
@@ -41,8 +58,11 @@ func main() {
 	botApiUrl := os.Getenv("BOT_API_URL")
 	mainChatId := os.Getenv("MAIN_CHAT_ID")
 	supportChatId := os.Getenv("SUPPORT_CHAT_ID")
-	nextAllowedUserIds := parseSemicolonSeparatedList(os.Getenv("NEXT_ALLOWED_USER_IDS"))
+	escalationUserIds := parseSemicolonSeparatedList(os.Getenv("ESCALATION_USER_IDS"))
+	// acknowledgement timeout in minutes, 0 disables acknowledgements and escalation
+	ackTimeout := lib.GetEnvVariableValueWithDefault("ACK_TIMEOUT", "0")
 	botType := os.Getenv("BOT_TYPE")
+	apiToken := os.Getenv("API_TOKEN")
 
 	// retry count for bot
 	retryCount := lib.GetEnvVariableValueWithDefault("RETRY_COUNT", "3")
@@ -64,46 +84,147 @@ func main() {
 		RetryPause:      retryPause,
 	}
 
-	connectionStr := os.Getenv("CONNECTION_STR")
-	if err := dao.ValidateConnection(connectionStr); err != nil {
+	db, err := dao.OpenDB(os.Getenv("CONNECTION_STR"), dao.PoolConfig{
+		MaxOpenConns:    lib.GetEnvVariableValueWithDefault("DB_MAX_OPEN_CONNS", "10"),
+		MaxIdleConns:    lib.GetEnvVariableValueWithDefault("DB_MAX_IDLE_CONNS", "5"),
+		ConnMaxLifetime: time.Duration(lib.GetEnvVariableValueWithDefault("DB_CONN_MAX_LIFETIME", "300")) * time.Second,
+	})
+	if err != nil {
+		log.Fatalf("database pool creation failed: %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Printf("failed to close database pool: %v", err)
+		}
+	}()
+	if err := dao.ValidateConnection(db); err != nil {
 		log.Fatalf("database validation failed: %v", err)
 	}
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrateCommand(db, os.Args[2:], os.Stdout); err != nil {
+			log.Fatalf("migrate: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "calendar" {
+		unusualDays := working_calendar.NewUnusualDays(dao.NewPostgresRepository(db))
+		if err := runCalendarCommand(unusualDays, os.Args[2:], os.Stdout); err != nil {
+			log.Fatalf("calendar: %v", err)
+		}
+		return
+	}
+	// pending migrations are applied by default, so upgrades from the schema without teams keep starting
+	if os.Getenv("AUTO_MIGRATE") != "false" {
+		applied, err := dao.MigrateUp(db)
+		if err != nil {
+			log.Fatalf("database migration failed: %v", err)
+		}
+		log.Printf("applied %d database migrations", len(applied))
+	}
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, "watch_bot"))
+	repository := dao.NewPostgresRepository(db)
+	teams, err := repository.GetTeams()
+	if err != nil {
+		log.Fatalf("failed to load teams: %v", err)
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	bots.CreateBot(ctx, settings)
-
 	log.Printf("Current time: %v", time.Now().Format("02.01.2006 MST"))
-	workingCalendar := working_calendar.FillWorkingTime()
-	unusualDays, err := dao.GetUnusualDays(connectionStr, time.Now())
-	for _, day := range unusualDays {
-		fmt.Printf("Unusual day: %s\n", day.Format("2006-01-02"))
+	// past unusual days tell off-day shifts apart in the duty statistics,
+	// changes from \holiday and the API are applied to the loaded days directly
+	unusualDaysSince := func() time.Time {
+		return time.Now().AddDate(0, 0, -duty.MaxStatsDays)
 	}
-	if err != nil {
+	unusualDays := working_calendar.NewUnusualDays(repository)
+	if err := unusualDays.Load(unusualDaysSince()); err != nil {
 		log.Printf("Error getting unusual days: %v", err)
 	}
+	for _, day := range unusualDays.Days() {
+		fmt.Printf("Unusual day: %s %s\n", day.Date.Format("2006-01-02"), day.TypeOrDefault())
+	}
+	// refresh interval of unusual days in minutes, 0 disables the refresh
+	unusualDaysRefreshInterval := lib.GetEnvVariableValueWithDefault("UNUSUAL_DAYS_REFRESH_INTERVAL", "60")
+	if unusualDaysRefreshInterval > 0 {
+		go unusualDays.RunRefresher(ctx, time.Duration(unusualDaysRefreshInterval)*time.Minute, unusualDaysSince)
+	}
+	// holidays of an iCalendar file or URL, imported on startup and every CALENDAR_IMPORT_INTERVAL hours
+	if calendarSource := os.Getenv("CALENDAR_IMPORT_SOURCE"); calendarSource != "" {
+		calendarImportInterval := lib.GetEnvVariableValueWithDefault("CALENDAR_IMPORT_INTERVAL", "24")
+		go runCalendarImporter(ctx, unusualDays, calendarSource, time.Duration(calendarImportInterval)*time.Hour)
+	}
+	prometheus.MustRegister(metrics.NewUnusualDaysCollector(unusualDays))
 
-	// Initialize command router
+	// Initialize command router, every team registers its own commands for its chats
 	commandRouter := bots.NewCommandRouter()
-	isWorkingNow := func() bool {
-		return working_calendar.IsWorkingTime(workingCalendar, time.Now(), unusualDays)
-	}
-	commandRouter.Register("duty", bots.NewChatRestrictedHandler(commands.NewDutyCommand(commands.DutyCommandConfig{
-		ConnectionStr: connectionStr,
-		MessagesChan:  botMessagesChannel,
-		SupportChatId: settings.SupportChatId,
-		IsWorkingNow:  isWorkingNow,
-	}), settings.MainChatId))
-	if settings.SupportChatId != "" {
-		commandRouter.Register("next", bots.NewChatRestrictedHandler(commands.NewNextCommand(commands.NextCommandConfig{
-			ConnectionStr:      connectionStr,
-			MessagesChan:       botMessagesChannel,
-			SupportChatId:      settings.SupportChatId,
-			AllowedNextUserIds: nextAllowedUserIds,
-			IsWorkingNow:       isWorkingNow,
-		}), settings.SupportChatId))
+	deps := teamDependencies{
+		repository:    repository,
+		commandRouter: commandRouter,
+		messagesChan:  botMessagesChannel,
+		unusualDays:   unusualDays,
+		ackTimeout:    time.Duration(ackTimeout) * time.Minute,
+	}
+	// the default team falls back to the environment variables
+	envTeam := dao.Team{
+		MainChatId:         mainChatId,
+		SupportChatId:      supportChatId,
+		NextAllowedUserIds: os.Getenv("NEXT_ALLOWED_USER_IDS"),
+		StartTime:          os.Getenv("START_TIME"),
+		EndTime:            os.Getenv("END_TIME"),
+		DaysOff:            os.Getenv("DAYS_OFF"),
+		WorkingSchedule:    os.Getenv("WORKING_SCHEDULE"),
+		Timezone:           os.Getenv("CALENDAR_TIMEZONE"),
+		RotationPeriod:     os.Getenv("ROTATION_PERIOD"),
+		RotationStrategy:   os.Getenv("ROTATION_STRATEGY"),
+	}
+	scheduleHandlers := make(map[string]http.Handler)
+	calendarHandlers := make(map[string]http.Handler)
+	pageHandlers := make(map[string]http.Handler)
+	alertmanagerHandlers := make(map[string]http.Handler)
+	statsHandlers := make(map[string]http.Handler)
+	teamStats := make(map[string]metrics.StatsFunc)
+	var escalationTrackers []*escalation.Tracker
+	defaultTeamName := ""
+	chatOwners := make(map[string]string)
+	for _, team := range teams {
+		var teamEscalationUserIds []string
+		if team.ID == dao.DefaultTeamID {
+			team = teamWithDefaults(team, envTeam)
+			teamEscalationUserIds = escalationUserIds
+			defaultTeamName = team.Name
+			settings.MainChatId = team.MainChatId
+			settings.SupportChatId = team.SupportChatId
+		} else {
+			if team.MainChatId == "" {
+				log.Printf("Skipping team %q without main chat", team.Name)
+				continue
+			}
+			settings.TeamChatIds = append(settings.TeamChatIds, team.MainChatId, team.SupportChatId)
+		}
+		if err := claimTeamChats(chatOwners, team); err != nil {
+			log.Fatalf("invalid teams: %v", err)
+		}
+		handlers, tracker := registerTeam(deps, team, teamEscalationUserIds)
+		scheduleHandlers[team.Name] = handlers.schedule
+		calendarHandlers[team.Name] = handlers.calendar
+		pageHandlers[team.Name] = handlers.page
+		alertmanagerHandlers[team.Name] = handlers.alertmanager
+		statsHandlers[team.Name] = handlers.stats
+		teamStats[team.Name] = handlers.getStats
+		if tracker != nil {
+			escalationTrackers = append(escalationTrackers, tracker)
+		}
+	}
+	prometheus.MustRegister(metrics.NewStatsCollector(statsMetricsDays, teamStats))
+	if len(escalationTrackers) > 0 {
+		// \ack is sent from the private chat with the bot, so it is not restricted to configured chats
+		commandRouter.Register("ack", commands.NewAckCommand(commands.AckCommandConfig{
+			Trackers: escalationTrackers,
+		}))
 	}
+
+	bots.CreateBot(ctx, settings)
 	go commandRouter.Listen(ctx, botCommandsChannel, botMessagesChannel)
 
 	isReady := &atomic.Value{}
@@ -114,9 +235,21 @@ func main() {
 	httpRouter.Use(middleware.Recoverer)
 
 	httpRouter.HandleFunc("/health", lib.Healthz)
-	httpRouter.HandleFunc("/ready", lib.Readyz(isReady))
+	httpRouter.HandleFunc("/ready", lib.Readyz(isReady, unusualDaysReadiness(unusualDays)))
 	httpRouter.Handle("/metrics", promhttp.Handler())
 
+	httpRouter.Get("/api/v1/schedule", api.ForTeam(scheduleHandlers, defaultTeamName))
+	httpRouter.Get("/api/v1/stats", api.ForTeam(statsHandlers, defaultTeamName))
+	httpRouter.Get("/api/v1/unusual-days", api.UnusualDays(unusualDays))
+	if apiToken == "" {
+		log.Printf("API_TOKEN is not set, authenticated API endpoints and the Alertmanager webhook will reject all requests")
+	}
+	httpRouter.With(api.RequireToken(apiToken)).Post("/webhooks/alertmanager", api.ForTeam(alertmanagerHandlers, defaultTeamName))
+	httpRouter.With(api.RequireToken(apiToken)).Post("/api/v1/page", api.ForTeam(pageHandlers, defaultTeamName))
+	httpRouter.With(api.RequireToken(apiToken)).Post("/api/v1/unusual-days", api.AddUnusualDay(unusualDays))
+	httpRouter.With(api.RequireToken(apiToken)).Delete("/api/v1/unusual-days/{date}", api.RemoveUnusualDay(unusualDays))
+	httpRouter.Get("/calendar/{file}", api.ForTeam(calendarHandlers, defaultTeamName))
+
 	httpServer := &http.Server{
 		Addr:    ":9000",
 		Handler: httpRouter,
@@ -158,6 +291,25 @@ func shutdownHTTPServer(httpServer *http.Server, isReady *atomic.Value) {
 	}
 }
 
+// unusualDaysReadiness reports the state of the unusual days refresher on /ready
+func unusualDaysReadiness(unusualDays *working_calendar.UnusualDays) lib.ReadinessDetail {
+	return func() (string, any) {
+		status := unusualDays.Status()
+		state := struct {
+			LastRefresh *time.Time `json:"last_refresh,omitempty"`
+			Error       string     `json:"error,omitempty"`
+			Days        int        `json:"days"`
+		}{Days: len(unusualDays.Days())}
+		if !status.LastRefresh.IsZero() {
+			state.LastRefresh = &status.LastRefresh
+		}
+		if status.Err != nil {
+			state.Error = status.Err.Error()
+		}
+		return "unusual_days", state
+	}
+}
+
 func parseSemicolonSeparatedList(value string) []string {
 	var result []string
 	for _, item := range strings.Split(value, ";") {
@@ -1,8 +1,18 @@
 package main
 
 import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
+	"watch_bot/dao"
+	"watch_bot/working_calendar"
 )
 
 func TestParseSemicolonSeparatedList(t *testing.T) {
@@ -22,3 +32,131 @@ func TestParseSemicolonSeparatedListDoesNotSplitCommas(t *testing.T) {
 		t.Fatalf("expected %v, got %v", expected, result)
 	}
 }
+
+func TestRunMigrateCommandRejectsUnknownAction(t *testing.T) {
+	for _, args := range [][]string{nil, {"sideways"}, {"up", "extra"}} {
+		if err := runMigrateCommand(nil, args, io.Discard); err == nil {
+			t.Fatalf("expected usage error for args %v", args)
+		}
+	}
+}
+
+func TestRunCalendarCommandRejectsUnknownAction(t *testing.T) {
+	for _, args := range [][]string{nil, {"import"}, {"export", "holidays.ics"}, {"import", "a.ics", "b.ics"}} {
+		if err := runCalendarCommand(nil, args, io.Discard); err == nil {
+			t.Fatalf("expected usage error for args %v", args)
+		}
+	}
+}
+
+const testHolidays = "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20260101\r\nDTEND;VALUE=DATE:20260103\r\n" +
+	"SUMMARY:New Year\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+
+func TestRunCalendarCommandImportsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "holidays.ics")
+	if err := os.WriteFile(path, []byte(testHolidays), 0o600); err != nil {
+		t.Fatalf("failed to write calendar: %v", err)
+	}
+	repo := dao.NewMemoryRepository()
+	repo.AddUnusualDay(dao.UnusualDay{Date: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), Type: dao.UnusualDayWorking})
+
+	var out bytes.Buffer
+	if err := runCalendarCommand(working_calendar.NewUnusualDays(repo), []string{"import", path}, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != "imported holidays.ics: 1 added, 0 updated, 1 kept as entered by hand\n" {
+		t.Errorf("unexpected output %q", out.String())
+	}
+	days, _ := repo.GetUnusualDays(time.Time{})
+	if len(days) != 2 || days[0].Source != "holidays.ics" || days[1].Type != dao.UnusualDayWorking || days[1].Source != "" {
+		t.Errorf("expected the manual day to be kept, got %+v", days)
+	}
+}
+
+func TestImportCalendarDownloadsURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/private-secret/basic.ics" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, testHolidays)
+	}))
+	defer server.Close()
+	repo := dao.NewMemoryRepository()
+	unusualDays := working_calendar.NewUnusualDays(repo)
+
+	summary, err := importCalendar(unusualDays, server.URL+"/private-secret/basic.ics")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Added != 2 {
+		t.Errorf("expected two holidays, got %+v", summary)
+	}
+	if days := unusualDays.Days(); len(days) != 2 || strings.Contains(days[0].Source, "secret") {
+		t.Errorf("expected the days to be applied and the source to omit the path, got %+v", days)
+	}
+	if _, err := importCalendar(unusualDays, server.URL+"/missing.ics"); err == nil {
+		t.Error("expected an error for a failed download")
+	}
+}
+
+func TestTeamWithDefaultsKeepsTeamSettings(t *testing.T) {
+	defaults := dao.Team{MainChatId: "env-main", SupportChatId: "env-support", StartTime: "09:00", EndTime: "18:00"}
+	team := teamWithDefaults(dao.Team{Name: "default", SupportChatId: "db-support", EndTime: "20:00"}, defaults)
+
+	expected := dao.Team{Name: "default", MainChatId: "env-main", SupportChatId: "db-support", StartTime: "09:00", EndTime: "20:00"}
+	if team != expected {
+		t.Fatalf("expected %+v, got %+v", expected, team)
+	}
+}
+
+func TestTeamWithDefaultsWorkingSchedule(t *testing.T) {
+	defaults := dao.Team{StartTime: "09:00", EndTime: "18:00", WorkingSchedule: "Mon-Fri 10:00-19:00"}
+
+	if team := teamWithDefaults(dao.Team{Name: "default"}, defaults); team.WorkingSchedule != defaults.WorkingSchedule {
+		t.Errorf("expected the schedule from the environment, got %+v", team)
+	}
+	if team := teamWithDefaults(dao.Team{Name: "default", StartTime: "08:00"}, defaults); team.WorkingSchedule != "" {
+		t.Errorf("expected the working hours of the team to win over the schedule from the environment, got %+v", team)
+	}
+}
+
+func TestTeamWithDefaultsWorkingTime(t *testing.T) {
+	defaults := dao.Team{StartTime: "09:00", EndTime: "18:00", DaysOff: "Saturday,Sunday", WorkingSchedule: "Mon-Sun 10:00-19:00"}
+	saturday := time.Date(2024, 3, 23, 12, 0, 0, 0, time.Local)
+
+	workingTime, err := working_calendar.TeamWorkingTime(teamWithDefaults(dao.Team{Name: "default"}, defaults))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !working_calendar.IsWorkingDay(workingTime, saturday, nil) {
+		t.Error("expected the schedule from the environment to replace the days off")
+	}
+
+	workingTime, err = working_calendar.TeamWorkingTime(teamWithDefaults(dao.Team{Name: "default", StartTime: "08:00"}, defaults))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if working_calendar.IsWorkingDay(workingTime, saturday, nil) {
+		t.Error("expected the working hours of the team to keep the days off from the environment")
+	}
+	if !working_calendar.IsWorkingTime(workingTime, time.Date(2024, 3, 18, 8, 30, 0, 0, time.Local), nil) {
+		t.Error("expected the start time of the team")
+	}
+}
+
+func TestClaimTeamChatsRejectsSharedChats(t *testing.T) {
+	owners := make(map[string]string)
+	if err := claimTeamChats(owners, dao.Team{Name: "default", MainChatId: "main", SupportChatId: "main"}); err != nil {
+		t.Fatalf("expected one team to use the same chat twice, got %v", err)
+	}
+	if err := claimTeamChats(owners, dao.Team{Name: "payments", MainChatId: "payments-main"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := claimTeamChats(owners, dao.Team{Name: "search", MainChatId: "search-main", SupportChatId: "payments-main"}); err == nil {
+		t.Error("expected an error for a support chat of another team")
+	}
+	if err := claimTeamChats(owners, dao.Team{Name: "billing", MainChatId: "main"}); err == nil {
+		t.Error("expected an error for a main chat of another team")
+	}
+}
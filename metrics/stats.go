@@ -0,0 +1,64 @@
+// Package metrics exports the duty statistics to Prometheus
+package metrics
+
+import (
+	"fmt"
+	"log"
+
+	"watch_bot/duty"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// StatsFunc returns the duty statistics of a team over the given number of days
+type StatsFunc func(days int) ([]duty.PersonStats, error)
+
+// StatsCollector reports the duty statistics of every team as gauges labelled by team and duty_id.
+// The statistics are computed on every scrape, so they are always in line with \stats.
+type StatsCollector struct {
+	days         int
+	teams        map[string]StatsFunc
+	dutyDays     *prometheus.Desc
+	nextSkips    *prometheus.Desc
+	pages        *prometheus.Desc
+	offDayShifts *prometheus.Desc
+}
+
+// NewStatsCollector creates a collector of the statistics over the last days days for the teams by name
+func NewStatsCollector(days int, teams map[string]StatsFunc) *StatsCollector {
+	labels := []string{"team", "duty_id"}
+	period := fmt.Sprintf(" over the last %d days", days)
+	return &StatsCollector{
+		days:         days,
+		teams:        teams,
+		dutyDays:     prometheus.NewDesc("watch_bot_duty_days", "Days on primary duty"+period, labels, nil),
+		nextSkips:    prometheus.NewDesc("watch_bot_duty_next_skips", "Times the duty was moved to somebody else with \\next"+period, labels, nil),
+		pages:        prometheus.NewDesc("watch_bot_duty_pages", "Calls received as primary or backup"+period, labels, nil),
+		offDayShifts: prometheus.NewDesc("watch_bot_duty_off_day_shifts", "Duty days on days off or unusual days"+period, labels, nil),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *StatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.dutyDays
+	ch <- c.nextSkips
+	ch <- c.pages
+	ch <- c.offDayShifts
+}
+
+// Collect implements prometheus.Collector, a team whose statistics fail is left out of the scrape
+func (c *StatsCollector) Collect(ch chan<- prometheus.Metric) {
+	for team, getStats := range c.teams {
+		stats, err := getStats(c.days)
+		if err != nil {
+			log.Printf("failed to collect duty statistics of team %q: %v", team, err)
+			continue
+		}
+		for _, person := range stats {
+			ch <- prometheus.MustNewConstMetric(c.dutyDays, prometheus.GaugeValue, float64(person.DutyDays), team, person.DutyID)
+			ch <- prometheus.MustNewConstMetric(c.nextSkips, prometheus.GaugeValue, float64(person.NextSkips), team, person.DutyID)
+			ch <- prometheus.MustNewConstMetric(c.pages, prometheus.GaugeValue, float64(person.Pages), team, person.DutyID)
+			ch <- prometheus.MustNewConstMetric(c.offDayShifts, prometheus.GaugeValue, float64(person.OffDayShifts), team, person.DutyID)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"watch_bot/duty"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestStatsCollector_Collect(t *testing.T) {
+	requestedDays := 0
+	collector := NewStatsCollector(30, map[string]StatsFunc{
+		"core": func(days int) ([]duty.PersonStats, error) {
+			requestedDays = days
+			return []duty.PersonStats{{DutyID: "alice", DutyDays: 5, NextSkips: 1, Pages: 3, OffDayShifts: 2}}, nil
+		},
+		"broken": func(int) ([]duty.PersonStats, error) {
+			return nil, errors.New("db down")
+		},
+	})
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requestedDays != 30 {
+		t.Errorf("expected statistics over 30 days, got %d", requestedDays)
+	}
+	values := make(map[string]float64)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			var labels []string
+			for _, label := range metric.GetLabel() {
+				labels = append(labels, label.GetName()+"="+label.GetValue())
+			}
+			values[family.GetName()+"{"+strings.Join(labels, ",")+"}"] = metric.GetGauge().GetValue()
+		}
+	}
+	expected := map[string]float64{
+		"watch_bot_duty_days{duty_id=alice,team=core}":           5,
+		"watch_bot_duty_next_skips{duty_id=alice,team=core}":     1,
+		"watch_bot_duty_pages{duty_id=alice,team=core}":          3,
+		"watch_bot_duty_off_day_shifts{duty_id=alice,team=core}": 2,
+	}
+	if len(values) != len(expected) {
+		t.Fatalf("expected %d metrics, got %v", len(expected), values)
+	}
+	for name, value := range expected {
+		if values[name] != value {
+			t.Errorf("expected %s = %v, got %v", name, value, values[name])
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"watch_bot/dao"
+	"watch_bot/working_calendar"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// UnusualDaysSource is the refreshed set of unusual days, see working_calendar.UnusualDays
+type UnusualDaysSource interface {
+	Days() []dao.UnusualDay
+	Status() working_calendar.RefreshStatus
+}
+
+// UnusualDaysCollector reports the state of the unusual days refresher
+type UnusualDaysCollector struct {
+	source      UnusualDaysSource
+	lastRefresh *prometheus.Desc
+	success     *prometheus.Desc
+	days        *prometheus.Desc
+}
+
+// NewUnusualDaysCollector creates a collector of the unusual days refresher state
+func NewUnusualDaysCollector(source UnusualDaysSource) *UnusualDaysCollector {
+	return &UnusualDaysCollector{
+		source:      source,
+		lastRefresh: prometheus.NewDesc("watch_bot_unusual_days_last_refresh_timestamp_seconds", "Time of the last successful load of the unusual days, 0 before the first one", nil, nil),
+		success:     prometheus.NewDesc("watch_bot_unusual_days_refresh_success", "Whether the last load of the unusual days succeeded", nil, nil),
+		days:        prometheus.NewDesc("watch_bot_unusual_days", "Number of unusual days in use", nil, nil),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *UnusualDaysCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.lastRefresh
+	ch <- c.success
+	ch <- c.days
+}
+
+// Collect implements prometheus.Collector
+func (c *UnusualDaysCollector) Collect(ch chan<- prometheus.Metric) {
+	status := c.source.Status()
+	lastRefresh, success := 0.0, 1.0
+	if !status.LastRefresh.IsZero() {
+		lastRefresh = float64(status.LastRefresh.UnixNano()) / 1e9
+	}
+	if status.Err != nil || status.LastRefresh.IsZero() {
+		success = 0
+	}
+	ch <- prometheus.MustNewConstMetric(c.lastRefresh, prometheus.GaugeValue, lastRefresh)
+	ch <- prometheus.MustNewConstMetric(c.success, prometheus.GaugeValue, success)
+	ch <- prometheus.MustNewConstMetric(c.days, prometheus.GaugeValue, float64(len(c.source.Days())))
+}
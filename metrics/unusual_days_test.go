@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"watch_bot/dao"
+	"watch_bot/working_calendar"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type staticUnusualDays struct {
+	days   []dao.UnusualDay
+	status working_calendar.RefreshStatus
+}
+
+func (s staticUnusualDays) Days() []dao.UnusualDay                 { return s.days }
+func (s staticUnusualDays) Status() working_calendar.RefreshStatus { return s.status }
+
+func gatherGauges(t *testing.T, collector prometheus.Collector) map[string]float64 {
+	t.Helper()
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	values := make(map[string]float64)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			values[family.GetName()] = metric.GetGauge().GetValue()
+		}
+	}
+	return values
+}
+
+func TestUnusualDaysCollector_Collect(t *testing.T) {
+	refreshedAt := time.Date(2026, 1, 8, 10, 0, 0, 0, time.UTC)
+	source := staticUnusualDays{
+		days:   []dao.UnusualDay{{Date: refreshedAt}, {Date: refreshedAt.AddDate(0, 0, 1)}},
+		status: working_calendar.RefreshStatus{LastRefresh: refreshedAt, Err: errors.New("db down")},
+	}
+
+	values := gatherGauges(t, NewUnusualDaysCollector(source))
+	if values["watch_bot_unusual_days_last_refresh_timestamp_seconds"] != float64(refreshedAt.Unix()) {
+		t.Errorf("unexpected refresh time %v", values["watch_bot_unusual_days_last_refresh_timestamp_seconds"])
+	}
+	if values["watch_bot_unusual_days_refresh_success"] != 0 {
+		t.Error("expected the failed refresh to be reported")
+	}
+	if values["watch_bot_unusual_days"] != 2 {
+		t.Errorf("expected 2 days, got %v", values["watch_bot_unusual_days"])
+	}
+
+	source.status.Err = nil
+	if values := gatherGauges(t, NewUnusualDaysCollector(source)); values["watch_bot_unusual_days_refresh_success"] != 1 {
+		t.Error("expected the successful refresh to be reported")
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"watch_bot/dao"
+)
+
+const migrateUsage = "usage: watch_bot migrate up|down|status"
+
+// runMigrateCommand executes the `migrate` subcommand
+func runMigrateCommand(db *sql.DB, args []string, out io.Writer) error {
+	if len(args) != 1 {
+		return errors.New(migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := dao.MigrateUp(db)
+		for _, migration := range applied {
+			fmt.Fprintf(out, "applied %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Fprintln(out, "database schema is up to date")
+		}
+	case "down":
+		migration, err := dao.MigrateDown(db)
+		if err != nil {
+			return err
+		}
+		if migration == nil {
+			fmt.Fprintln(out, "no applied migrations to roll back")
+			return nil
+		}
+		fmt.Fprintf(out, "rolled back %04d_%s\n", migration.Version, migration.Name)
+	case "status":
+		statuses, err := dao.GetMigrationStatus(db)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.AppliedAt != nil {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Fprintf(out, "%04d_%s\t%s\n", status.Version, status.Name, state)
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+	"watch_bot/api"
+	"watch_bot/bots"
+	"watch_bot/bots/commands"
+	"watch_bot/dao"
+	"watch_bot/duty"
+	"watch_bot/escalation"
+	"watch_bot/metrics"
+	"watch_bot/working_calendar"
+)
+
+// teamDependencies are shared by the rotations of all teams
+type teamDependencies struct {
+	repository    *dao.PostgresRepository
+	commandRouter *bots.CommandRouter
+	messagesChan  chan bots.Message
+	unusualDays   *working_calendar.UnusualDays
+	ackTimeout    time.Duration
+}
+
+// teamHandlers are the HTTP handlers bound to the rotation of a single team
+type teamHandlers struct {
+	schedule     http.Handler
+	calendar     http.Handler
+	page         http.Handler
+	alertmanager http.Handler
+	stats        http.Handler
+	// getStats feeds the duty statistics gauges on /metrics
+	getStats metrics.StatsFunc
+}
+
+// teamWithDefaults fills the empty settings of the team from defaults.
+// It keeps single-team deployments configured by environment variables working as before.
+func teamWithDefaults(team dao.Team, defaults dao.Team) dao.Team {
+	fill := func(value *string, fallback string) {
+		if *value == "" {
+			*value = fallback
+		}
+	}
+	fill(&team.MainChatId, defaults.MainChatId)
+	fill(&team.SupportChatId, defaults.SupportChatId)
+	fill(&team.NextAllowedUserIds, defaults.NextAllowedUserIds)
+	// working hours of the team are not mixed with the schedule from the environment
+	if team.StartTime == "" && team.EndTime == "" {
+		fill(&team.WorkingSchedule, defaults.WorkingSchedule)
+	}
+	fill(&team.StartTime, defaults.StartTime)
+	fill(&team.EndTime, defaults.EndTime)
+	fill(&team.DaysOff, defaults.DaysOff)
+	fill(&team.Timezone, defaults.Timezone)
+	fill(&team.RotationPeriod, defaults.RotationPeriod)
+	fill(&team.RotationStrategy, defaults.RotationStrategy)
+	return team
+}
+
+// claimTeamChats records the chats of the team in owners, a map of chat IDs to team names. Commands are
+// routed by chat, so it fails when a chat already belongs to another team instead of letting the later
+// team take over its commands. The main and support chat of one team may be the same.
+func claimTeamChats(owners map[string]string, team dao.Team) error {
+	for _, chatId := range []string{team.MainChatId, team.SupportChatId} {
+		if chatId == "" {
+			continue
+		}
+		if owner, ok := owners[chatId]; ok && owner != team.Name {
+			return fmt.Errorf("teams %q and %q share chat %q, every team needs its own chats", owner, team.Name, chatId)
+		}
+		owners[chatId] = team.Name
+	}
+	return nil
+}
+
+// registerTeam registers the bot commands of the team for its chats and returns its HTTP handlers
+// together with its acknowledgement tracker, which is nil when acknowledgements are disabled
+func registerTeam(deps teamDependencies, team dao.Team, escalationUserIds []string) (teamHandlers, *escalation.Tracker) {
+	log.Printf("Team %q: main chat %q, support chat %q", team.Name, team.MainChatId, team.SupportChatId)
+	repository := deps.repository.ForTeam(team.ID)
+	workingCalendar, err := working_calendar.TeamWorkingTime(team)
+	if err != nil {
+		log.Fatalf("team %q: %v", team.Name, err)
+	}
+	location := workingCalendar.Location
+	isWorkingNow := func() bool {
+		return working_calendar.IsWorkingTime(workingCalendar, time.Now(), deps.unusualDays.Days())
+	}
+	isWorkingDay := func(date time.Time) bool {
+		return working_calendar.IsWorkingDay(workingCalendar, date, deps.unusualDays.Days())
+	}
+	isRegularDay := func(date time.Time) bool {
+		return working_calendar.IsRegularDay(workingCalendar, date, deps.unusualDays.Days())
+	}
+	rotation, err := duty.ParseRotation(team.RotationPeriod)
+	if err != nil {
+		log.Fatalf("team %q: invalid rotation period: %v", team.Name, err)
+	}
+	rotation.Strategy, err = duty.ParseRotationStrategy(team.RotationStrategy)
+	if err != nil {
+		log.Fatalf("team %q: %v", team.Name, err)
+	}
+	rotation.IsWorkingDay = isWorkingDay
+	log.Printf("Team %q: %s rotation, strategy %q, time zone %s", team.Name, rotation, team.RotationStrategy, location)
+	dutyService := duty.NewService(repository).WithRotation(rotation).WithLocation(location)
+
+	var escalationTracker *escalation.Tracker
+	if deps.ackTimeout > 0 {
+		escalationTracker = escalation.NewTracker(escalation.Config{
+			Timeout:           deps.ackTimeout,
+			EscalationUserIds: escalationUserIds,
+			NextInRotation:    dutyService.GetEscalationCandidate,
+			MessagesChan:      deps.messagesChan,
+			SupportChatId:     team.SupportChatId,
+		})
+	}
+
+	router := deps.commandRouter
+	dutyCommand := commands.NewDutyCommand(commands.DutyCommandConfig{
+		Repository:    repository,
+		MessagesChan:  deps.messagesChan,
+		SupportChatId: team.SupportChatId,
+		IsWorkingNow:  isWorkingNow,
+		Rotation:      rotation,
+		Location:      location,
+		Escalation:    escalationTracker,
+	})
+	router.RegisterForChats("duty", dutyCommand, team.MainChatId)
+	router.RegisterForChats("schedule", commands.NewScheduleCommand(commands.ScheduleCommandConfig{
+		Repository:   repository,
+		IsWorkingDay: isWorkingDay,
+		Rotation:     rotation,
+		Location:     location,
+	}), team.MainChatId, team.SupportChatId)
+	router.RegisterForChats("stats", commands.NewStatsCommand(commands.StatsCommandConfig{
+		Repository:   repository,
+		IsRegularDay: isRegularDay,
+		Location:     location,
+	}), team.MainChatId, team.SupportChatId)
+	if team.SupportChatId != "" {
+		allowedUserIds := parseSemicolonSeparatedList(team.NextAllowedUserIds)
+		router.RegisterForChats("next", commands.NewNextCommand(commands.NextCommandConfig{
+			Repository:         repository,
+			MessagesChan:       deps.messagesChan,
+			SupportChatId:      team.SupportChatId,
+			AllowedNextUserIds: allowedUserIds,
+			IsWorkingNow:       isWorkingNow,
+			Rotation:           rotation,
+			Location:           location,
+		}), team.SupportChatId)
+		memberConfig := commands.MemberCommandConfig{
+			Repository:     repository,
+			AllowedUserIds: allowedUserIds,
+		}
+		router.RegisterForChats("members", commands.NewMembersCommand(memberConfig), team.SupportChatId)
+		router.RegisterForChats("add", commands.NewAddMemberCommand(memberConfig), team.SupportChatId)
+		router.RegisterForChats("remove", commands.NewRemoveMemberCommand(memberConfig), team.SupportChatId)
+		// unusual days are shared by all teams, so only the default team manages them
+		if team.ID == dao.DefaultTeamID {
+			router.RegisterForChats("holiday", commands.NewHolidayCommand(commands.HolidayCommandConfig{
+				UnusualDays:    deps.unusualDays,
+				AllowedUserIds: allowedUserIds,
+				IsWorkingDay:   isWorkingDay,
+				Location:       location,
+			}), team.SupportChatId)
+		}
+		router.RegisterForChats("history", commands.NewHistoryCommand(commands.HistoryCommandConfig{
+			Repository: repository,
+			Location:   location,
+		}), team.SupportChatId)
+		absenceConfig := commands.AbsenceCommandConfig{
+			Repository: repository,
+			Location:   location,
+		}
+		router.RegisterForChats("away", commands.NewAwayCommand(absenceConfig), team.SupportChatId)
+		router.RegisterForChats("back", commands.NewBackCommand(absenceConfig), team.SupportChatId)
+		swapConfig := commands.SwapCommandConfig{
+			Repository:   repository,
+			MessagesChan: deps.messagesChan,
+			Location:     location,
+			IsWorkingDay: isWorkingDay,
+			Rotation:     rotation,
+		}
+		router.RegisterForChats("swap", commands.NewSwapCommand(swapConfig), team.SupportChatId)
+		router.RegisterForChats("accept", commands.NewAcceptSwapCommand(swapConfig), team.SupportChatId)
+		router.RegisterForChats("decline", commands.NewDeclineSwapCommand(swapConfig), team.SupportChatId)
+	}
+
+	getStats := func(days int) ([]duty.PersonStats, error) {
+		return dutyService.GetStats(days, isRegularDay)
+	}
+	return teamHandlers{
+		schedule: api.Schedule(func(days int) ([]duty.ScheduledDay, error) {
+			return dutyService.GetSchedule(days, isWorkingDay)
+		}),
+		calendar: api.Calendar(func() ([]duty.ScheduledDay, error) {
+			return dutyService.GetDutyDays(calendarPastDays, calendarFutureDays, isWorkingDay)
+		}),
+		page: api.Page(dutyCommand.Call),
+		alertmanager: api.Alertmanager(api.AlertmanagerConfig{
+			GetCurrentDuty:  dutyService.GetCurrentDuty,
+			PeekCurrentDuty: dutyService.PeekCurrentDuty,
+			IsWorkingDay: func() bool {
+				return isWorkingDay(duty.DateIn(time.Now(), location))
+			},
+			MessagesChan:  deps.messagesChan,
+			SupportChatId: team.SupportChatId,
+			RecordPage:    dutyService.RecordPage,
+		}),
+		stats:    api.Stats(getStats),
+		getStats: getStats,
+	}, escalationTracker
+}
@@ -0,0 +1,87 @@
+package working_calendar
+
+import (
+	"log"
+	"sort"
+	"watch_bot/dao"
+	"watch_bot/ical"
+)
+
+// ImportSummary counts the outcomes of an import of unusual days
+type ImportSummary struct {
+	Added   int
+	Updated int
+	// Skipped counts the dates entered by hand, they are never overwritten by an import
+	Skipped int
+}
+
+// MaxImportedEventDays is the longest event turned into holidays, longer events are taken for mistakes
+// of the calendar, such as an end in a wrong year, and skipped
+const MaxImportedEventDays = 31
+
+// HolidaysOf returns a holiday for every date covered by the events, in chronological order and without
+// repeated dates. source is recorded on every day, so that later imports can update them.
+// Events longer than MaxImportedEventDays are skipped and logged.
+func HolidaysOf(events []ical.Event, source string) []dao.UnusualDay {
+	seen := make(map[string]bool)
+	var days []dao.UnusualDay
+	for _, event := range events {
+		if event.End.After(dateOf(event.Start).AddDate(0, 0, MaxImportedEventDays)) {
+			log.Printf("skipping event %q of %s from %s to %s, it is longer than %d days", event.Summary, source,
+				event.Start.Format("2006-01-02"), event.End.Format("2006-01-02"), MaxImportedEventDays)
+			continue
+		}
+		for date := dateOf(event.Start); date.Before(event.End); date = date.AddDate(0, 0, 1) {
+			key := dateKey(date)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			days = append(days, dao.UnusualDay{Date: date, Type: dao.UnusualDayHoliday, Source: source})
+		}
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Date.Before(days[j].Date)
+	})
+	return days
+}
+
+// Import stores the imported days. Days entered by hand on the same dates are kept, days of earlier
+// imports get the type and hours of the new ones. The stored days apply immediately, also when
+// the import stops at an error.
+func (u *UnusualDays) Import(days []dao.UnusualDay) (ImportSummary, error) {
+	var summary ImportSummary
+	for _, day := range days {
+		if err := ValidateUnusualDay(day); err != nil {
+			return summary, err
+		}
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	stored := make(map[string]dao.UnusualDay)
+	defer func() {
+		if len(stored) > 0 {
+			u.replaceDays(stored)
+		}
+	}()
+	for _, day := range days {
+		outcome, err := u.repository.ImportUnusualDay(day)
+		if err != nil {
+			return summary, err
+		}
+		switch outcome {
+		case dao.ImportAdded:
+			summary.Added++
+		case dao.ImportUpdated:
+			summary.Updated++
+		default:
+			summary.Skipped++
+			continue
+		}
+		day.Date = dateOf(day.Date)
+		day.Type = day.TypeOrDefault()
+		stored[dateKey(day.Date)] = day
+	}
+	return summary, nil
+}
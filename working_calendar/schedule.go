@@ -0,0 +1,219 @@
+package working_calendar
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Window is a working interval of a day, both bounds are offsets from midnight and belong to the window.
+// An overnight window ends after 24h and belongs to the day it starts on.
+type Window struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+func (w Window) String() string {
+	end := w.End
+	if end > day {
+		end -= day
+	}
+	return formatClock(w.Start) + "-" + formatClock(end)
+}
+
+// contains reports whether the time of day falls into the window
+func (w Window) contains(clock time.Duration) bool {
+	return clock >= w.Start && clock <= w.End
+}
+
+// WeeklySchedule holds the working windows of every weekday, a weekday without windows is a day off
+type WeeklySchedule map[time.Weekday][]Window
+
+// day is the length of a calendar day in the windows, daylight saving time changes are not accounted for
+const day = 24 * time.Hour
+
+// AlwaysOn is the schedule value of a calendar that works around the clock on every day,
+// unlike a calendar without working time it still has holidays
+const AlwaysOn = "24/7"
+
+// AlwaysOnSchedule returns the schedule of a calendar working around the clock on every day
+func AlwaysOnSchedule() WeeklySchedule {
+	schedule := make(WeeklySchedule)
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		schedule[weekday] = []Window{{Start: 0, End: day}}
+	}
+	return schedule
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// ParseWeeklySchedule parses semicolon-separated entries of weekdays followed by comma-separated windows,
+// e.g. "Mon-Thu 10:00-13:00,14:00-19:00; Fri 10:00-13:00,14:00-17:00". Weekdays are names or ranges
+// separated by commas, a weekday listed twice gets the windows of both entries. A window ending
+// before it starts, such as "22:00-06:00", crosses midnight, "24:00" ends a window at midnight.
+// AlwaysOn is the schedule working around the clock.
+func ParseWeeklySchedule(value string) (WeeklySchedule, error) {
+	if strings.TrimSpace(value) == AlwaysOn {
+		return AlwaysOnSchedule(), nil
+	}
+	schedule := make(WeeklySchedule)
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		daysPart, windowsPart, found := strings.Cut(entry, " ")
+		if !found {
+			return nil, fmt.Errorf("schedule entry %q has no working hours", entry)
+		}
+		weekdays, err := parseWeekdays(daysPart)
+		if err != nil {
+			return nil, err
+		}
+		var windows []Window
+		for _, windowStr := range strings.Split(windowsPart, ",") {
+			window, err := parseWindow(strings.TrimSpace(windowStr))
+			if err != nil {
+				return nil, err
+			}
+			windows = append(windows, window)
+		}
+		for _, weekday := range weekdays {
+			schedule[weekday] = append(schedule[weekday], windows...)
+		}
+	}
+	if len(schedule) == 0 {
+		return nil, fmt.Errorf("schedule %q has no working days", value)
+	}
+
+	for weekday, windows := range schedule {
+		sort.Slice(windows, func(i, j int) bool {
+			return windows[i].Start < windows[j].Start
+		})
+		for i := 1; i < len(windows); i++ {
+			if windows[i].Start < windows[i-1].End {
+				return nil, fmt.Errorf("working hours %s and %s of %s overlap", windows[i-1], windows[i], weekday)
+			}
+		}
+	}
+	return schedule, nil
+}
+
+// DaysOff returns the weekdays without working windows
+func (s WeeklySchedule) DaysOff() []time.Weekday {
+	var daysOff []time.Weekday
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if len(s[weekday]) == 0 {
+			daysOff = append(daysOff, weekday)
+		}
+	}
+	return daysOff
+}
+
+// IsAlwaysOn reports whether every weekday is working around the clock
+func (s WeeklySchedule) IsAlwaysOn() bool {
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		windows := s[weekday]
+		if len(windows) != 1 || windows[0].Start != 0 || windows[0].End < day {
+			return false
+		}
+	}
+	return true
+}
+
+func (s WeeklySchedule) String() string {
+	if s.IsAlwaysOn() {
+		return AlwaysOn
+	}
+	var entries []string
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if windows := s[weekday]; len(windows) > 0 {
+			hours := make([]string, len(windows))
+			for i, window := range windows {
+				hours[i] = window.String()
+			}
+			entries = append(entries, weekday.String()[:3]+" "+strings.Join(hours, ","))
+		}
+	}
+	return strings.Join(entries, "; ")
+}
+
+// parseWeekdays parses comma-separated weekday names and ranges such as "Mon-Thu" or "Fri-Mon"
+func parseWeekdays(value string) ([]time.Weekday, error) {
+	var weekdays []time.Weekday
+	for _, part := range strings.Split(value, ",") {
+		fromStr, toStr, isRange := strings.Cut(part, "-")
+		from, ok := weekdayNames[strings.ToLower(strings.TrimSpace(fromStr))]
+		if !ok {
+			return nil, fmt.Errorf("unknown weekday %q", fromStr)
+		}
+		to := from
+		if isRange {
+			if to, ok = weekdayNames[strings.ToLower(strings.TrimSpace(toStr))]; !ok {
+				return nil, fmt.Errorf("unknown weekday %q", toStr)
+			}
+		}
+		for weekday := from; ; weekday = (weekday + 1) % 7 {
+			weekdays = append(weekdays, weekday)
+			if weekday == to {
+				break
+			}
+		}
+	}
+	return weekdays, nil
+}
+
+// parseWindow parses "HH:MM-HH:MM" working hours, the window crosses midnight when it ends before it starts
+func parseWindow(value string) (Window, error) {
+	startStr, endStr, found := strings.Cut(value, "-")
+	if !found {
+		return Window{}, fmt.Errorf("invalid working hours %q, expected HH:MM-HH:MM", value)
+	}
+	start, err := parseClock(startStr)
+	if err != nil {
+		return Window{}, err
+	}
+	end, err := parseClock(endStr)
+	if err != nil {
+		return Window{}, err
+	}
+	if start >= day || start == end {
+		return Window{}, fmt.Errorf("invalid working hours %q", value)
+	}
+	if end < start {
+		end += day
+	}
+	return Window{Start: start, End: end}, nil
+}
+
+// parseClock parses "HH:MM" into the offset from midnight, "24:00" is the midnight of the next day
+func parseClock(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "24:00" {
+		return day, nil
+	}
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return clockOf(parsed), nil
+}
+
+// clockOf returns the time of day of t as the offset from midnight
+func clockOf(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+}
+
+func formatClock(clock time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(clock/time.Hour), int(clock%time.Hour/time.Minute))
+}
@@ -0,0 +1,97 @@
+package working_calendar
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseWeeklySchedule(t *testing.T) {
+	schedule, err := ParseWeeklySchedule("Mon-Thu 10:00-13:00,14:00-19:00; friday 14:00-17:00, 10:00-13:00")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lunchBreak := []Window{{Start: 10 * time.Hour, End: 13 * time.Hour}, {Start: 14 * time.Hour, End: 19 * time.Hour}}
+	for _, weekday := range []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday} {
+		if !reflect.DeepEqual(schedule[weekday], lunchBreak) {
+			t.Errorf("unexpected windows of %s: %v", weekday, schedule[weekday])
+		}
+	}
+	if got := schedule[time.Friday]; len(got) != 2 || got[0].String() != "10:00-13:00" || got[1].String() != "14:00-17:00" {
+		t.Errorf("expected sorted Friday windows, got %v", got)
+	}
+	if !reflect.DeepEqual(schedule.DaysOff(), []time.Weekday{time.Sunday, time.Saturday}) {
+		t.Errorf("unexpected days off %v", schedule.DaysOff())
+	}
+	if schedule.String() != "Mon 10:00-13:00,14:00-19:00; Tue 10:00-13:00,14:00-19:00; Wed 10:00-13:00,14:00-19:00; "+
+		"Thu 10:00-13:00,14:00-19:00; Fri 10:00-13:00,14:00-17:00" {
+		t.Errorf("unexpected string %q", schedule.String())
+	}
+}
+
+func TestParseWeeklySchedule_WeekdayLists(t *testing.T) {
+	schedule, err := ParseWeeklySchedule("Sat,Mon 10:00-12:00;Fri-Sun 12:00-14:00")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(schedule[time.Monday]) != 1 || len(schedule[time.Friday]) != 1 || len(schedule[time.Sunday]) != 1 {
+		t.Errorf("unexpected schedule %v", schedule)
+	}
+	if len(schedule[time.Saturday]) != 2 {
+		t.Errorf("expected Saturday to get the windows of both entries, got %v", schedule[time.Saturday])
+	}
+}
+
+func TestParseWeeklySchedule_Invalid(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"Mon-Fri",
+		"Funday 10:00-19:00",
+		"Mon 10:00",
+		"Mon 10am-7pm",
+		"Mon 10:00-10:00",
+		"Mon 24:00-06:00",
+		"Mon 22:00-06:00,23:00-23:30",
+		"Mon 10:00-14:00,13:00-19:00",
+		"Mon 10:00-12:00; Mon 11:00-13:00",
+	} {
+		if _, err := ParseWeeklySchedule(value); err == nil {
+			t.Errorf("expected an error for %q", value)
+		}
+	}
+}
+
+func TestParseWeeklySchedule_Overnight(t *testing.T) {
+	schedule, err := ParseWeeklySchedule("Mon-Fri 22:00-06:00; Sat 00:00-24:00")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := schedule[time.Monday]; len(got) != 1 || got[0] != (Window{Start: 22 * time.Hour, End: 30 * time.Hour}) {
+		t.Errorf("expected the Monday window to end on Tuesday, got %v", got)
+	}
+	if schedule.String() != "Mon 22:00-06:00; Tue 22:00-06:00; Wed 22:00-06:00; Thu 22:00-06:00; Fri 22:00-06:00; Sat 00:00-24:00" {
+		t.Errorf("unexpected string %q", schedule.String())
+	}
+	if schedule.IsAlwaysOn() {
+		t.Error("expected a schedule with days off not to be always on")
+	}
+}
+
+func TestParseWeeklySchedule_AlwaysOn(t *testing.T) {
+	schedule, err := ParseWeeklySchedule(AlwaysOn)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !schedule.IsAlwaysOn() || len(schedule.DaysOff()) != 0 || schedule.String() != AlwaysOn {
+		t.Errorf("unexpected always on schedule %v", schedule)
+	}
+
+	spelledOut, err := ParseWeeklySchedule("Mon-Sun 00:00-24:00")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !spelledOut.IsAlwaysOn() {
+		t.Errorf("expected every day around the clock to be always on, got %v", spelledOut)
+	}
+}
@@ -0,0 +1,163 @@
+package working_calendar
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+	"watch_bot/dao"
+)
+
+// UnusualDays keeps the unusual days in memory for the working time checks. Changes are written
+// through to the repository and the days are reloaded from it periodically, so they apply without a restart.
+type UnusualDays struct {
+	repository dao.CalendarRepository
+	// mu serializes the writers, readers load days without locking
+	mu sync.Mutex
+	// days is swapped as a whole on every change and never modified in place
+	days   atomic.Pointer[[]dao.UnusualDay]
+	status atomic.Pointer[RefreshStatus]
+}
+
+// RefreshStatus describes the last attempt to load the unusual days from the repository
+type RefreshStatus struct {
+	// LastRefresh is the time of the last successful load, zero before the first one
+	LastRefresh time.Time
+	// Err is the error of the last attempt, nil when it succeeded
+	Err error
+}
+
+// NewUnusualDays creates an empty set of unusual days backed by the repository
+func NewUnusualDays(repository dao.CalendarRepository) *UnusualDays {
+	return &UnusualDays{repository: repository}
+}
+
+// Load replaces the days with the ones stored on or after since and records the outcome in Status.
+// The loaded days stay in use when loading fails.
+func (u *UnusualDays) Load(since time.Time) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	days, err := u.repository.GetUnusualDays(since)
+	status := u.Status()
+	status.Err = err
+	if err == nil {
+		status.LastRefresh = time.Now()
+		u.days.Store(&days)
+	}
+	u.status.Store(&status)
+	return err
+}
+
+// RunRefresher reloads the days every interval until the context is cancelled.
+// since returns the lower bound of every load, so that past days are dropped over time.
+func (u *UnusualDays) RunRefresher(ctx context.Context, interval time.Duration, since func() time.Time) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := u.Load(since()); err != nil {
+				log.Printf("failed to refresh unusual days: %v", err)
+			}
+		}
+	}
+}
+
+// Status returns the outcome of the last load
+func (u *UnusualDays) Status() RefreshStatus {
+	if status := u.status.Load(); status != nil {
+		return *status
+	}
+	return RefreshStatus{}
+}
+
+// Days returns all known unusual days, the slice must not be modified
+func (u *UnusualDays) Days() []dao.UnusualDay {
+	if days := u.days.Load(); days != nil {
+		return *days
+	}
+	return nil
+}
+
+// From returns the unusual days on or after the date in chronological order
+func (u *UnusualDays) From(date time.Time) []dao.UnusualDay {
+	from := dateOf(date)
+	var days []dao.UnusualDay
+	for _, day := range u.Days() {
+		if !dateOf(day.Date).Before(from) {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// Add stores the unusual day, replacing the type and hours of an existing one on the same date.
+// It reports false when the date already was an unusual day.
+func (u *UnusualDays) Add(day dao.UnusualDay) (bool, error) {
+	if err := ValidateUnusualDay(day); err != nil {
+		return false, err
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	added, err := u.repository.AddUnusualDay(day)
+	if err != nil {
+		return false, err
+	}
+	day.Date = dateOf(day.Date)
+	day.Type = day.TypeOrDefault()
+	u.replaceDays(map[string]dao.UnusualDay{dateKey(day.Date): day})
+	return added, nil
+}
+
+// Remove deletes the unusual day, it reports false when the date was not one
+func (u *UnusualDays) Remove(day time.Time) (bool, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	removed, err := u.repository.RemoveUnusualDay(day)
+	if err != nil || !removed {
+		return removed, err
+	}
+	date := dateOf(day)
+	days := make([]dao.UnusualDay, 0, len(u.Days()))
+	for _, existing := range u.Days() {
+		if !dateOf(existing.Date).Equal(date) {
+			days = append(days, existing)
+		}
+	}
+	u.days.Store(&days)
+	return true, nil
+}
+
+// replaceDays swaps in the given days in place of the loaded ones on the same dates, u.mu must be held
+func (u *UnusualDays) replaceDays(replacements map[string]dao.UnusualDay) {
+	days := make([]dao.UnusualDay, 0, len(u.Days())+len(replacements))
+	for _, existing := range u.Days() {
+		if _, replaced := replacements[dateKey(dateOf(existing.Date))]; !replaced {
+			days = append(days, existing)
+		}
+	}
+	for _, day := range replacements {
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Date.Before(days[j].Date)
+	})
+	u.days.Store(&days)
+}
+
+// dateKey identifies the calendar date of t in maps of days
+func dateKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// dateOf keeps only the calendar date of t, the way the repository stores it
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
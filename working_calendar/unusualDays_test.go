@@ -0,0 +1,175 @@
+package working_calendar
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"watch_bot/dao"
+	"watch_bot/ical"
+)
+
+func TestUnusualDays_ChangesApplyImmediately(t *testing.T) {
+	repo := dao.NewMemoryRepository()
+	repo.AddUnusualDay(dao.UnusualDay{Date: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)})
+	unusualDays := NewUnusualDays(repo)
+	if err := unusualDays.Load(time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	workingTime := ParseWorkingTime("09:00", "18:00", "Saturday,Sunday")
+	friday := time.Date(2026, 5, 1, 12, 0, 0, 0, time.Local)
+	if !IsWorkingTime(workingTime, friday, unusualDays.Days()) {
+		t.Fatal("expected a regular Friday to be working")
+	}
+
+	if added, err := unusualDays.Add(dao.UnusualDay{Date: friday}); err != nil || !added {
+		t.Fatalf("expected the day to be added, got %v, %v", added, err)
+	}
+	if IsWorkingTime(workingTime, friday, unusualDays.Days()) {
+		t.Error("expected the new holiday to apply without reloading")
+	}
+	if added, _ := unusualDays.Add(dao.UnusualDay{Date: friday, Type: dao.UnusualDayShortened, EndTime: "11:00"}); added {
+		t.Error("expected the same day not to be added twice")
+	}
+	if days := unusualDays.From(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)); len(days) != 1 || days[0].Date.Month() != time.May {
+		t.Errorf("expected only the May day, got %v", days)
+	}
+	if IsWorkingTime(workingTime, friday, unusualDays.Days()) {
+		t.Error("expected the day to end at 11:00 after its type was changed")
+	}
+	if _, err := unusualDays.Add(dao.UnusualDay{Date: friday, Type: dao.UnusualDayHoliday, EndTime: "11:00"}); err == nil {
+		t.Error("expected an invalid day to be rejected")
+	}
+
+	if removed, err := unusualDays.Remove(friday); err != nil || !removed {
+		t.Fatalf("expected the day to be removed, got %v, %v", removed, err)
+	}
+	if !IsWorkingTime(workingTime, friday, unusualDays.Days()) {
+		t.Error("expected the removal to apply without reloading")
+	}
+	if stored, _ := repo.GetUnusualDays(time.Time{}); len(stored) != 1 {
+		t.Errorf("expected the changes to be written through, got %v", stored)
+	}
+}
+
+// flakyCalendar fails to load the unusual days while err is set
+type flakyCalendar struct {
+	*dao.MemoryRepository
+	err error
+}
+
+func (f *flakyCalendar) GetUnusualDays(currentDate time.Time) ([]dao.UnusualDay, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.MemoryRepository.GetUnusualDays(currentDate)
+}
+
+func TestUnusualDays_LoadKeepsDaysOnError(t *testing.T) {
+	repo := &flakyCalendar{MemoryRepository: dao.NewMemoryRepository()}
+	repo.AddUnusualDay(dao.UnusualDay{Date: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)})
+	unusualDays := NewUnusualDays(repo)
+
+	if status := unusualDays.Status(); !status.LastRefresh.IsZero() || status.Err != nil {
+		t.Errorf("expected an empty status before the first load, got %+v", status)
+	}
+	if err := unusualDays.Load(time.Time{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	loadedAt := unusualDays.Status().LastRefresh
+	if loadedAt.IsZero() {
+		t.Fatal("expected the refresh time to be recorded")
+	}
+
+	repo.err = errors.New("db down")
+	if err := unusualDays.Load(time.Time{}); err == nil {
+		t.Fatal("expected the load to fail")
+	}
+	status := unusualDays.Status()
+	if status.Err == nil || !status.LastRefresh.Equal(loadedAt) {
+		t.Errorf("expected the error and the previous refresh time, got %+v", status)
+	}
+	if len(unusualDays.Days()) != 1 {
+		t.Errorf("expected the loaded days to stay in use, got %v", unusualDays.Days())
+	}
+}
+
+func TestUnusualDays_RunRefresher(t *testing.T) {
+	repo := dao.NewMemoryRepository()
+	unusualDays := NewUnusualDays(repo)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		unusualDays.RunRefresher(ctx, time.Millisecond, func() time.Time { return time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC) })
+		close(done)
+	}()
+
+	// inserted behind the back of the store, like a manual SQL insert
+	repo.AddUnusualDay(dao.UnusualDay{Date: time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)})
+	repo.AddUnusualDay(dao.UnusualDay{Date: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)})
+	deadline := time.Now().Add(time.Second)
+	for len(unusualDays.Days()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if days := unusualDays.Days(); len(days) != 1 || days[0].Date.Month() != time.March {
+		t.Errorf("expected the refresher to pick up only the new day after the lower bound, got %v", days)
+	}
+}
+
+func TestUnusualDays_Import(t *testing.T) {
+	repo := dao.NewMemoryRepository()
+	unusualDays := NewUnusualDays(repo)
+	manual := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	if _, err := unusualDays.Add(dao.UnusualDay{Date: manual, Type: dao.UnusualDayWorking}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	days := HolidaysOf([]ical.Event{
+		{Start: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC)},
+		{Start: time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC), End: time.Date(2026, 1, 4, 0, 0, 0, 0, time.UTC)},
+	}, "holidays.ics")
+	if len(days) != 3 {
+		t.Fatalf("expected one holiday per date, got %+v", days)
+	}
+	summary, err := unusualDays.Import(days)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary != (ImportSummary{Added: 2, Skipped: 1}) {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+	if loaded := unusualDays.Days(); len(loaded) != 3 || loaded[1].Type != dao.UnusualDayWorking || loaded[2].Source != "holidays.ics" {
+		t.Errorf("expected the imported days next to the manual one without reloading, got %+v", loaded)
+	}
+
+	summary, err = unusualDays.Import(days)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary != (ImportSummary{Updated: 2, Skipped: 1}) {
+		t.Errorf("expected a repeated import to update its own days, got %+v", summary)
+	}
+	if stored, _ := repo.GetUnusualDays(time.Time{}); len(stored) != 3 {
+		t.Errorf("expected no duplicated days, got %+v", stored)
+	}
+}
+
+func TestHolidaysOf_SkipsOverlongEvents(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	days := HolidaysOf([]ical.Event{
+		{Summary: "broken", Start: start, End: time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{Summary: "longest", Start: start.AddDate(0, 2, 0), End: start.AddDate(0, 2, MaxImportedEventDays)},
+		{Summary: "one too long", Start: start.AddDate(0, 6, 0), End: start.AddDate(0, 6, MaxImportedEventDays+1)},
+	}, "holidays.ics")
+
+	if len(days) != MaxImportedEventDays {
+		t.Fatalf("expected only the days of the longest allowed event, got %d", len(days))
+	}
+	if !days[0].Date.Equal(start.AddDate(0, 2, 0)) {
+		t.Errorf("expected the first day to be %v, got %v", start.AddDate(0, 2, 0), days[0].Date)
+	}
+}
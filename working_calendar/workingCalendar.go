@@ -1,19 +1,42 @@
 package working_calendar
 
 import (
+	"fmt"
 	"log"
-	"os"
 	"strings"
 	"time"
+	"watch_bot/dao"
 )
 
 type WorkingTime struct {
-	StartTime      time.Time
-	EndTime        time.Time
-	DaysOff        []time.Weekday
+	StartTime time.Time
+	EndTime   time.Time
+	DaysOff   []time.Weekday
+	// Schedule replaces StartTime and EndTime with the windows of every weekday, nil for the same hours every day
+	Schedule WeeklySchedule
+	// Location is the time zone of the working hours, nil evaluates them in the time zone of the checked time
+	Location       *time.Location
 	hasWorkingTime bool
 }
 
+// In returns the working time evaluated in the location
+func (w WorkingTime) In(location *time.Location) WorkingTime {
+	w.Location = location
+	return w
+}
+
+// LoadLocation loads the IANA time zone of the calendar, the local time zone when the name is empty
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %w", name, err)
+	}
+	return location, nil
+}
+
 func contains(weekdays []time.Weekday, day time.Weekday) bool {
 	for _, d := range weekdays {
 		if d == day {
@@ -23,63 +46,232 @@ func contains(weekdays []time.Weekday, day time.Weekday) bool {
 	return false
 }
 
-func IsWorkingTime(workingTime WorkingTime, currentTime time.Time, unusualDays []time.Time) bool {
+// IsWorkingTime reports whether currentTime falls into a working window of its day or into an overnight
+// window of the previous day. Unusual days change whether the day is a working one and may have their own hours,
+// a window crossing midnight follows the calendar day it starts on.
+func IsWorkingTime(workingTime WorkingTime, currentTime time.Time, unusualDays []dao.UnusualDay) bool {
 
 	if !workingTime.hasWorkingTime {
 		return true
 	}
 
-	currentWeekday := currentTime.Weekday()
+	if workingTime.Location != nil {
+		currentTime = currentTime.In(workingTime.Location)
+	}
+	clock := clockOf(currentTime)
+	if workingTime.isWorkingAt(currentTime, clock, unusualDays) {
+		return true
+	}
+	return workingTime.isWorkingAt(currentTime.AddDate(0, 0, -1), clock+day, unusualDays)
+
+}
 
-	isUnusualDay := isUnusualDay(currentTime, unusualDays)
+// isWorkingAt reports whether the clock, counted from the midnight of the date, falls into a working window of the date
+func (w WorkingTime) isWorkingAt(date time.Time, clock time.Duration, unusualDays []dao.UnusualDay) bool {
+	unusualDay, isUnusualDay := findUnusualDay(date, unusualDays)
+	if isUnusualDay && unusualDay.TypeOrDefault() == dao.UnusualDayInverted && contains(w.DaysOff, date.Weekday()) {
+		// an inverted day off is working around the clock, as before unusual days had a type
+		return clock < day
+	}
+	if !IsWorkingDay(w, date, unusualDays) {
+		return false
+	}
+
+	windows := w.windowsOn(date.Weekday())
+	if isUnusualDay {
+		windows = windowsOf(unusualDay, w, date.Weekday())
+	}
+	for _, window := range windows {
+		if window.contains(clock) {
+			return true
+		}
+	}
+	return false
+}
 
-	if contains(workingTime.DaysOff, currentWeekday) {
-		return isUnusualDay
+// IsWorkingDay reports whether the date is a working day, ignoring the time of day.
+// The date is a calendar date, its year, month and day are taken as they are.
+// Inverted unusual days turn days off into working days and working days into days off,
+// holidays are days off, working and shortened days are working days.
+func IsWorkingDay(workingTime WorkingTime, date time.Time, unusualDays []dao.UnusualDay) bool {
+	if !workingTime.hasWorkingTime {
+		return true
 	}
 
-	currentTimeTimeOnly := time.Date(0, 1, 1, currentTime.Hour(), currentTime.Minute(), currentTime.Second(), currentTime.Nanosecond(), currentTime.Location())
-	if currentTimeTimeOnly.Before(workingTime.StartTime) || currentTimeTimeOnly.After(workingTime.EndTime) {
+	isDayOff := contains(workingTime.DaysOff, date.Weekday())
+	unusualDay, isUnusualDay := findUnusualDay(date, unusualDays)
+	if !isUnusualDay {
+		return !isDayOff
+	}
+	switch unusualDay.TypeOrDefault() {
+	case dao.UnusualDayHoliday:
 		return false
+	case dao.UnusualDayWorking, dao.UnusualDayShortened:
+		return true
+	default:
+		return isDayOff
 	}
+}
+
+// IsRegularDay reports whether the date is an ordinary working day: neither a day off nor an unusual day
+func IsRegularDay(workingTime WorkingTime, date time.Time, unusualDays []dao.UnusualDay) bool {
+	return !contains(workingTime.DaysOff, date.Weekday()) && !isUnusualDay(date, unusualDays)
+}
 
-	return !isUnusualDay
+// ValidateUnusualDay checks the type and the hours of an unusual day. Only working and shortened days
+// may have hours, and a day with both bounds must start before it ends.
+func ValidateUnusualDay(day dao.UnusualDay) error {
+	switch day.TypeOrDefault() {
+	case dao.UnusualDayWorking, dao.UnusualDayShortened:
+	case dao.UnusualDayInverted, dao.UnusualDayHoliday:
+		if day.StartTime != "" || day.EndTime != "" {
+			return fmt.Errorf("%s days have no working hours", day.TypeOrDefault())
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown unusual day type %q", day.Type)
+	}
 
+	var bounds []time.Time
+	for _, value := range []string{day.StartTime, day.EndTime} {
+		if value == "" {
+			continue
+		}
+		bound, err := time.Parse("15:04", value)
+		if err != nil {
+			return fmt.Errorf("invalid time %q, expected HH:MM", value)
+		}
+		bounds = append(bounds, bound)
+	}
+	if len(bounds) == 2 && !bounds[0].Before(bounds[1]) {
+		return fmt.Errorf("working hours %s-%s end before they start", day.StartTime, day.EndTime)
+	}
+	return nil
 }
 
-// isUnusualDay checks if the current date (ignoring time) matches any date in the unusual days list
-func isUnusualDay(currentTime time.Time, unusualDays []time.Time) bool {
-	// If no unusual days defined, return false
-	if len(unusualDays) == 0 {
-		return false
+// windowsOn returns the working windows of the weekday, nil on days off
+func (w WorkingTime) windowsOn(weekday time.Weekday) []Window {
+	if w.Schedule != nil {
+		return w.Schedule[weekday]
 	}
+	if contains(w.DaysOff, weekday) {
+		return nil
+	}
+	window := Window{Start: clockOf(w.StartTime), End: clockOf(w.EndTime)}
+	if window.End < window.Start {
+		window.End += day
+	}
+	return []Window{window}
+}
 
-	location, errLocation := time.LoadLocation("Local")
+// usualWindows returns the windows of the first working weekday from Monday,
+// they are the hours of a day off that became a working day
+func (w WorkingTime) usualWindows() []Window {
+	for i := 1; i <= 7; i++ {
+		if windows := w.windowsOn(time.Weekday(i % 7)); len(windows) > 0 {
+			return windows
+		}
+	}
+	return nil
+}
 
-	if errLocation != nil {
-		log.Printf("Error loading location: %v", errLocation)
-		location = time.UTC // fallback to UTC if location cannot be loaded
+// windowsOf returns the working windows of a working unusual day. Its own start and end replace the whole
+// day with a single window, a single bound only moves the start or the end of the usual windows.
+// A shortened day without its own end ends an hour before the usual time.
+func windowsOf(day dao.UnusualDay, workingTime WorkingTime, weekday time.Weekday) []Window {
+	windows := workingTime.windowsOn(weekday)
+	if len(windows) == 0 {
+		windows = workingTime.usualWindows()
+	}
+	if len(windows) == 0 {
+		return nil
 	}
 
-	// remove time component from current time, keeping only the date part
-	currentDate := time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), 0, 0, 0, 0, location)
+	start, end := windows[0].Start, windows[len(windows)-1].End
+	if day.TypeOrDefault() == dao.UnusualDayShortened {
+		end -= time.Hour
+	}
+	customStart, errStart := parseClock(day.StartTime)
+	if errStart == nil {
+		start = customStart
+	}
+	customEnd, errEnd := parseClock(day.EndTime)
+	if errEnd == nil {
+		end = customEnd
+		if end < start {
+			// the day keeps its usual overnight start
+			end += 24 * time.Hour
+		}
+	}
+	if errStart == nil && errEnd == nil {
+		return []Window{{Start: start, End: end}}
+	}
 
-	for _, unusualDay := range unusualDays {
-		// remove time component from unusual day, keeping only the date part
-		unusualDate := time.Date(unusualDay.Year(), unusualDay.Month(), unusualDay.Day(), 0, 0, 0, 0, location)
+	var result []Window
+	for i, window := range windows {
+		if i == 0 || window.Start < start {
+			window.Start = start
+		}
+		if i == len(windows)-1 || window.End > end {
+			window.End = end
+		}
+		if window.Start < window.End {
+			result = append(result, window)
+		}
+	}
+	return result
+}
 
-		if currentDate.Equal(unusualDate) {
-			return true
+// isUnusualDay checks if the current date (ignoring time) matches any date in the unusual days list
+func isUnusualDay(currentTime time.Time, unusualDays []dao.UnusualDay) bool {
+	_, ok := findUnusualDay(currentTime, unusualDays)
+	return ok
+}
+
+// findUnusualDay returns the unusual day on the date of currentTime, ignoring the time of day
+func findUnusualDay(currentTime time.Time, unusualDays []dao.UnusualDay) (dao.UnusualDay, bool) {
+	year, month, date := currentTime.Date()
+	for _, unusualDay := range unusualDays {
+		unusualYear, unusualMonth, unusualDate := unusualDay.Date.Date()
+		if year == unusualYear && month == unusualMonth && date == unusualDate {
+			return unusualDay, true
 		}
 	}
 
-	return false
+	return dao.UnusualDay{}, false
 }
 
-func FillWorkingTime() WorkingTime {
-	startTimeStr := os.Getenv("START_TIME")
-	endTimeStr := os.Getenv("END_TIME")
-	daysOffStr := os.Getenv("DAYS_OFF")
+// TeamWorkingTime builds the working time of the team in its time zone. The working schedule replaces
+// the start time, end time and days off when it is set. Invalid bounds disable the working time check,
+// an invalid schedule or time zone is an error.
+func TeamWorkingTime(team dao.Team) (WorkingTime, error) {
+	location, err := LoadLocation(team.Timezone)
+	if err != nil {
+		return WorkingTime{}, err
+	}
+	if team.WorkingSchedule == "" {
+		return ParseWorkingTime(team.StartTime, team.EndTime, team.DaysOff).In(location), nil
+	}
+	schedule, err := ParseWeeklySchedule(team.WorkingSchedule)
+	if err != nil {
+		return WorkingTime{}, fmt.Errorf("invalid working schedule: %w", err)
+	}
+	return NewWorkingTime(schedule).In(location), nil
+}
+
+// NewWorkingTime builds the working time from a weekly schedule, weekdays without windows are days off
+func NewWorkingTime(schedule WeeklySchedule) WorkingTime {
+	log.Printf("Working schedule: %s", schedule)
+	return WorkingTime{
+		DaysOff:        schedule.DaysOff(),
+		Schedule:       schedule,
+		hasWorkingTime: true,
+	}
+}
 
+// ParseWorkingTime builds the working time from "HH:MM" bounds and a comma-separated list of days off.
+// Working time is not checked when the bounds cannot be parsed.
+func ParseWorkingTime(startTimeStr, endTimeStr, daysOffStr string) WorkingTime {
 	location, errLocation := time.LoadLocation("Local")
 	if errLocation != nil {
 		log.Printf("Will not check working time. Error parsing location: %v", errLocation)
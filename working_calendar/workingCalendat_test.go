@@ -1,28 +1,37 @@
 package working_calendar
 
 import (
-	"os"
 	"reflect"
 	"testing"
 	"time"
+	"watch_bot/dao"
 )
 
-func TestFillWorkingTime(t *testing.T) {
+// invertedDays turns dates into unusual days without a type, like the rows created before types existed
+func invertedDays(dates ...time.Time) []dao.UnusualDay {
+	days := make([]dao.UnusualDay, 0, len(dates))
+	for _, date := range dates {
+		days = append(days, dao.UnusualDay{Date: date})
+	}
+	return days
+}
+
+func TestTeamWorkingTime(t *testing.T) {
 	location, _ := time.LoadLocation("Local")
 	startTime, _ := time.ParseInLocation("15:04", "09:00", location)
 	endTime, _ := time.ParseInLocation("15:04", "18:00", location)
 
 	tests := []struct {
-		name    string
-		envVars map[string]string
-		want    WorkingTime
+		name string
+		team dao.Team
+		want WorkingTime
 	}{
 		{
 			name: "valid working time",
-			envVars: map[string]string{
-				"START_TIME": "09:00",
-				"END_TIME":   "18:00",
-				"DAYS_OFF":   "Saturday,Sunday",
+			team: dao.Team{
+				StartTime: "09:00",
+				EndTime:   "18:00",
+				DaysOff:   "Saturday,Sunday",
 			},
 			want: WorkingTime{
 				StartTime:      startTime,
@@ -33,10 +42,10 @@ func TestFillWorkingTime(t *testing.T) {
 		},
 		{
 			name: "invalid time format",
-			envVars: map[string]string{
-				"START_TIME": "25:00",
-				"END_TIME":   "18:00",
-				"DAYS_OFF":   "Saturday,Sunday",
+			team: dao.Team{
+				StartTime: "25:00",
+				EndTime:   "18:00",
+				DaysOff:   "Saturday,Sunday",
 			},
 			want: WorkingTime{
 				hasWorkingTime: false,
@@ -44,10 +53,10 @@ func TestFillWorkingTime(t *testing.T) {
 		},
 		{
 			name: "empty environment variables",
-			envVars: map[string]string{
-				"START_TIME": "",
-				"END_TIME":   "",
-				"DAYS_OFF":   "",
+			team: dao.Team{
+				StartTime: "",
+				EndTime:   "",
+				DaysOff:   "",
 			},
 			want: WorkingTime{
 				hasWorkingTime: false,
@@ -55,10 +64,10 @@ func TestFillWorkingTime(t *testing.T) {
 		},
 		{
 			name: "multiple days off with spaces",
-			envVars: map[string]string{
-				"START_TIME": "09:00",
-				"END_TIME":   "18:00",
-				"DAYS_OFF":   "Saturday, Sunday, Monday",
+			team: dao.Team{
+				StartTime: "09:00",
+				EndTime:   "18:00",
+				DaysOff:   "Saturday, Sunday, Monday",
 			},
 			want: WorkingTime{
 				StartTime:      startTime,
@@ -69,10 +78,10 @@ func TestFillWorkingTime(t *testing.T) {
 		},
 		{
 			name: "days off not set",
-			envVars: map[string]string{
-				"START_TIME": "09:00",
-				"END_TIME":   "18:00",
-				"DAYS_OFF":   "",
+			team: dao.Team{
+				StartTime: "09:00",
+				EndTime:   "18:00",
+				DaysOff:   "",
 			},
 			want: WorkingTime{
 				StartTime:      startTime,
@@ -85,39 +94,30 @@ func TestFillWorkingTime(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Setup
-			for k, v := range tt.envVars {
-				os.Setenv(k, v)
+			got, err := TeamWorkingTime(tt.team)
+			if err != nil {
+				t.Fatalf("TeamWorkingTime() error = %v", err)
 			}
 
-			// Cleanup
-			defer func() {
-				for k := range tt.envVars {
-					os.Unsetenv(k)
-				}
-			}()
-
-			got := FillWorkingTime()
-
 			// Check hasWorkingTime
 			if got.hasWorkingTime != tt.want.hasWorkingTime {
-				t.Errorf("FillWorkingTime().hasWorkingTime = %v, want %v",
+				t.Errorf("TeamWorkingTime().hasWorkingTime = %v, want %v",
 					got.hasWorkingTime, tt.want.hasWorkingTime)
 			}
 
 			if got.hasWorkingTime {
 				// Check times
 				if !got.StartTime.Equal(tt.want.StartTime) {
-					t.Errorf("FillWorkingTime().StartTime = %v, want %v",
+					t.Errorf("TeamWorkingTime().StartTime = %v, want %v",
 						got.StartTime, tt.want.StartTime)
 				}
 				if !got.EndTime.Equal(tt.want.EndTime) {
-					t.Errorf("FillWorkingTime().EndTime = %v, want %v",
+					t.Errorf("TeamWorkingTime().EndTime = %v, want %v",
 						got.EndTime, tt.want.EndTime)
 				}
 				// Check days off
 				if !reflect.DeepEqual(got.DaysOff, tt.want.DaysOff) {
-					t.Errorf("FillWorkingTime().DaysOff = %v, want %v",
+					t.Errorf("TeamWorkingTime().DaysOff = %v, want %v",
 						got.DaysOff, tt.want.DaysOff)
 				}
 			}
@@ -125,6 +125,73 @@ func TestFillWorkingTime(t *testing.T) {
 	}
 }
 
+func TestTeamWorkingTime_ScheduleTakesPrecedence(t *testing.T) {
+	location, _ := time.LoadLocation("Local")
+	team := dao.Team{StartTime: "09:00", EndTime: "18:00", DaysOff: "Saturday,Sunday", WorkingSchedule: "Mon-Fri 10:00-19:00; Sat 10:00-14:00"}
+
+	workingTime, err := TeamWorkingTime(team)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if workingTime.Schedule == nil {
+		t.Fatal("expected the working schedule to replace the start and end time")
+	}
+	if IsWorkingTime(workingTime, time.Date(2024, 3, 18, 9, 30, 0, 0, location), nil) {
+		t.Error("expected the start time to be ignored")
+	}
+	if !IsWorkingTime(workingTime, time.Date(2024, 3, 18, 18, 30, 0, 0, location), nil) {
+		t.Error("expected the end time to be ignored")
+	}
+	if !IsWorkingDay(workingTime, time.Date(2024, 3, 23, 12, 0, 0, 0, location), nil) {
+		t.Error("expected the days off to be ignored")
+	}
+}
+
+func TestTeamWorkingTime_WithoutSchedule(t *testing.T) {
+	location, _ := time.LoadLocation("Local")
+	workingTime, err := TeamWorkingTime(dao.Team{StartTime: "09:00", EndTime: "18:00", DaysOff: "Saturday,Sunday"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if workingTime.Schedule != nil {
+		t.Fatalf("expected the same hours every day, got %v", workingTime.Schedule)
+	}
+	if !IsWorkingTime(workingTime, time.Date(2024, 3, 18, 9, 30, 0, 0, location), nil) {
+		t.Error("expected the start time to be used")
+	}
+	if IsWorkingDay(workingTime, time.Date(2024, 3, 23, 12, 0, 0, 0, location), nil) {
+		t.Error("expected the days off to be used")
+	}
+}
+
+func TestTeamWorkingTime_InvalidSchedule(t *testing.T) {
+	if _, err := TeamWorkingTime(dao.Team{StartTime: "09:00", EndTime: "18:00", WorkingSchedule: "Mon-Fri 10:00"}); err == nil {
+		t.Error("expected an error for an invalid working schedule")
+	}
+}
+
+func TestTeamWorkingTime_Timezone(t *testing.T) {
+	workingTime, err := TeamWorkingTime(dao.Team{StartTime: "09:00", EndTime: "18:00", Timezone: "Asia/Tokyo"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if workingTime.Location == nil || workingTime.Location.String() != "Asia/Tokyo" {
+		t.Fatalf("expected the time zone of the team, got %v", workingTime.Location)
+	}
+	if !IsWorkingTime(workingTime, time.Date(2024, 3, 19, 1, 0, 0, 0, time.UTC), nil) {
+		t.Error("expected the working hours to count in the time zone of the team")
+	}
+
+	for _, team := range []dao.Team{
+		{StartTime: "09:00", EndTime: "18:00", Timezone: "Mars/Olympus_Mons"},
+		{WorkingSchedule: "Mon-Fri 10:00-19:00", Timezone: "Mars/Olympus_Mons"},
+	} {
+		if _, err := TeamWorkingTime(team); err == nil {
+			t.Errorf("expected an error for an unknown time zone of %+v", team)
+		}
+	}
+}
+
 func TestIsWorkingTime(t *testing.T) {
 	location, _ := time.LoadLocation("Local")
 	// Use fixed times for test predictability
@@ -205,7 +272,7 @@ func TestIsWorkingTime(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := IsWorkingTime(tt.workingTime, tt.currentTime, []time.Time{})
+			got := IsWorkingTime(tt.workingTime, tt.currentTime, nil)
 			if got != tt.want {
 				t.Errorf("IsWorkingTime() = %v, want %v", got, tt.want)
 			}
@@ -267,10 +334,290 @@ func TestIsUnusualDay(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := isUnusualDay(tt.currentTime, tt.unusualDays)
+			got := isUnusualDay(tt.currentTime, invertedDays(tt.unusualDays...))
 			if got != tt.want {
 				t.Errorf("isUnusualDay() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestIsWorkingDay(t *testing.T) {
+	location, _ := time.LoadLocation("Local")
+	startTime, _ := time.ParseInLocation("15:04", "09:00", location)
+	endTime, _ := time.ParseInLocation("15:04", "18:00", location)
+	workingTime := WorkingTime{
+		StartTime:      startTime,
+		EndTime:        endTime,
+		DaysOff:        []time.Weekday{time.Saturday, time.Sunday},
+		hasWorkingTime: true,
+	}
+	unusualDays := []time.Time{
+		time.Date(2024, 3, 21, 0, 0, 0, 0, location), // Thursday holiday
+		time.Date(2024, 3, 23, 0, 0, 0, 0, location), // working Saturday
+	}
+
+	tests := []struct {
+		name        string
+		workingTime WorkingTime
+		date        time.Time
+		want        bool
+	}{
+		{"regular working day outside hours", workingTime, time.Date(2024, 3, 20, 23, 0, 0, 0, location), true},
+		{"holiday on weekday", workingTime, time.Date(2024, 3, 21, 12, 0, 0, 0, location), false},
+		{"working saturday", workingTime, time.Date(2024, 3, 23, 12, 0, 0, 0, location), true},
+		{"regular sunday", workingTime, time.Date(2024, 3, 24, 12, 0, 0, 0, location), false},
+		{"working time not set", WorkingTime{hasWorkingTime: false}, time.Date(2024, 3, 24, 12, 0, 0, 0, location), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsWorkingDay(tt.workingTime, tt.date, invertedDays(unusualDays...)); got != tt.want {
+				t.Errorf("IsWorkingDay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWorkingTime(t *testing.T) {
+	got := ParseWorkingTime("10:00", "19:00", "Friday,Saturday")
+	if !got.hasWorkingTime {
+		t.Fatal("expected working time to be set")
+	}
+	if got.StartTime.Format("15:04") != "10:00" || got.EndTime.Format("15:04") != "19:00" {
+		t.Errorf("unexpected working hours %v - %v", got.StartTime, got.EndTime)
+	}
+	if !reflect.DeepEqual(got.DaysOff, []time.Weekday{time.Friday, time.Saturday}) {
+		t.Errorf("unexpected days off %v", got.DaysOff)
+	}
+
+	if ParseWorkingTime("", "", "").hasWorkingTime {
+		t.Error("expected working time not to be checked without bounds")
+	}
+}
+
+func TestIsWorkingTime_TypedUnusualDays(t *testing.T) {
+	location, _ := time.LoadLocation("Local")
+	workingTime := ParseWorkingTime("09:00", "18:00", "Saturday,Sunday")
+	unusualDays := []dao.UnusualDay{
+		{Date: time.Date(2024, 3, 21, 0, 0, 0, 0, location), Type: dao.UnusualDayHoliday},
+		{Date: time.Date(2024, 3, 23, 0, 0, 0, 0, location), Type: dao.UnusualDayWorking, StartTime: "10:00", EndTime: "14:00"},
+		{Date: time.Date(2024, 3, 24, 0, 0, 0, 0, location), Type: dao.UnusualDayHoliday},
+		{Date: time.Date(2024, 3, 25, 0, 0, 0, 0, location), Type: dao.UnusualDayShortened},
+		{Date: time.Date(2024, 3, 26, 0, 0, 0, 0, location), Type: dao.UnusualDayShortened, EndTime: "15:00"},
+		{Date: time.Date(2024, 3, 30, 0, 0, 0, 0, location), Type: dao.UnusualDayWorking},
+	}
+
+	tests := []struct {
+		name        string
+		currentTime time.Time
+		want        bool
+	}{
+		{"holiday on weekday", time.Date(2024, 3, 21, 12, 0, 0, 0, location), false},
+		{"working saturday before custom start", time.Date(2024, 3, 23, 9, 30, 0, 0, location), false},
+		{"working saturday within custom hours", time.Date(2024, 3, 23, 12, 0, 0, 0, location), true},
+		{"working saturday after custom end", time.Date(2024, 3, 23, 15, 0, 0, 0, location), false},
+		{"holiday on day off", time.Date(2024, 3, 24, 12, 0, 0, 0, location), false},
+		{"shortened day before the hour cut", time.Date(2024, 3, 25, 16, 30, 0, 0, location), true},
+		{"shortened day within the hour cut", time.Date(2024, 3, 25, 17, 30, 0, 0, location), false},
+		{"shortened day with custom end", time.Date(2024, 3, 26, 15, 30, 0, 0, location), false},
+		{"working saturday with usual hours", time.Date(2024, 3, 30, 17, 0, 0, 0, location), true},
+		{"working saturday outside usual hours", time.Date(2024, 3, 30, 20, 0, 0, 0, location), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsWorkingTime(workingTime, tt.currentTime, unusualDays); got != tt.want {
+				t.Errorf("IsWorkingTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateUnusualDay(t *testing.T) {
+	date := time.Date(2024, 3, 23, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		day     dao.UnusualDay
+		wantErr bool
+	}{
+		{"legacy day without type", dao.UnusualDay{Date: date}, false},
+		{"holiday", dao.UnusualDay{Date: date, Type: dao.UnusualDayHoliday}, false},
+		{"working day with hours", dao.UnusualDay{Date: date, Type: dao.UnusualDayWorking, StartTime: "10:00", EndTime: "14:00"}, false},
+		{"shortened day with end only", dao.UnusualDay{Date: date, Type: dao.UnusualDayShortened, EndTime: "16:00"}, false},
+		{"unknown type", dao.UnusualDay{Date: date, Type: "vacation"}, true},
+		{"holiday with hours", dao.UnusualDay{Date: date, Type: dao.UnusualDayHoliday, StartTime: "10:00"}, true},
+		{"malformed time", dao.UnusualDay{Date: date, Type: dao.UnusualDayWorking, StartTime: "10am"}, true},
+		{"end before start", dao.UnusualDay{Date: date, Type: dao.UnusualDayWorking, StartTime: "14:00", EndTime: "10:00"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateUnusualDay(tt.day); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateUnusualDay() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIsWorkingTime_WeeklySchedule(t *testing.T) {
+	location, _ := time.LoadLocation("Local")
+	schedule, err := ParseWeeklySchedule("Mon-Thu 10:00-13:00,14:00-19:00; Fri 10:00-13:00,14:00-17:00")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	workingTime := NewWorkingTime(schedule)
+	unusualDays := []dao.UnusualDay{
+		{Date: time.Date(2024, 3, 23, 0, 0, 0, 0, location), Type: dao.UnusualDayWorking},
+		{Date: time.Date(2024, 3, 26, 0, 0, 0, 0, location), Type: dao.UnusualDayShortened},
+		{Date: time.Date(2024, 3, 27, 0, 0, 0, 0, location), Type: dao.UnusualDayWorking, StartTime: "12:00", EndTime: "16:00"},
+		{Date: time.Date(2024, 3, 28, 0, 0, 0, 0, location), Type: dao.UnusualDayShortened, StartTime: "13:30"},
+	}
+
+	tests := []struct {
+		name        string
+		currentTime time.Time
+		want        bool
+	}{
+		{"monday morning", time.Date(2024, 3, 18, 10, 30, 0, 0, location), true},
+		{"monday lunch break", time.Date(2024, 3, 18, 13, 30, 0, 0, location), false},
+		{"monday evening", time.Date(2024, 3, 18, 18, 30, 0, 0, location), true},
+		{"friday evening", time.Date(2024, 3, 22, 18, 0, 0, 0, location), false},
+		{"friday afternoon", time.Date(2024, 3, 22, 16, 30, 0, 0, location), true},
+		{"sunday", time.Date(2024, 3, 24, 12, 0, 0, 0, location), false},
+		{"working saturday follows monday", time.Date(2024, 3, 23, 18, 30, 0, 0, location), true},
+		{"working saturday keeps the lunch break", time.Date(2024, 3, 23, 13, 30, 0, 0, location), false},
+		{"shortened day ends an hour early", time.Date(2024, 3, 26, 18, 30, 0, 0, location), false},
+		{"shortened day keeps the lunch break", time.Date(2024, 3, 26, 13, 30, 0, 0, location), false},
+		{"custom hours replace the lunch break", time.Date(2024, 3, 27, 13, 30, 0, 0, location), true},
+		{"custom hours end the day", time.Date(2024, 3, 27, 16, 30, 0, 0, location), false},
+		{"custom start keeps the lunch break", time.Date(2024, 3, 28, 13, 45, 0, 0, location), false},
+		{"custom start afternoon", time.Date(2024, 3, 28, 17, 30, 0, 0, location), true},
+		{"custom start skips the morning", time.Date(2024, 3, 28, 11, 0, 0, 0, location), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsWorkingTime(workingTime, tt.currentTime, unusualDays); got != tt.want {
+				t.Errorf("IsWorkingTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if IsWorkingDay(workingTime, time.Date(2024, 3, 24, 12, 0, 0, 0, location), nil) {
+		t.Error("expected a weekday without windows to be a day off")
+	}
+}
+
+func TestIsWorkingTime_Overnight(t *testing.T) {
+	location, _ := time.LoadLocation("Local")
+	schedule, err := ParseWeeklySchedule("Mon-Fri 22:00-06:00")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	workingTime := NewWorkingTime(schedule)
+	unusualDays := []dao.UnusualDay{
+		{Date: time.Date(2024, 3, 20, 0, 0, 0, 0, location), Type: dao.UnusualDayHoliday},
+		{Date: time.Date(2024, 3, 23, 0, 0, 0, 0, location), Type: dao.UnusualDayWorking},
+		{Date: time.Date(2024, 3, 25, 0, 0, 0, 0, location), Type: dao.UnusualDayShortened},
+	}
+
+	tests := []struct {
+		name        string
+		currentTime time.Time
+		want        bool
+	}{
+		{"monday late evening", time.Date(2024, 3, 18, 23, 0, 0, 0, location), true},
+		{"tuesday early morning follows monday", time.Date(2024, 3, 19, 3, 0, 0, 0, location), true},
+		{"tuesday daytime", time.Date(2024, 3, 19, 12, 0, 0, 0, location), false},
+		{"holiday evening", time.Date(2024, 3, 20, 23, 0, 0, 0, location), false},
+		{"morning after holiday evening", time.Date(2024, 3, 21, 3, 0, 0, 0, location), false},
+		{"holiday morning after working evening", time.Date(2024, 3, 20, 3, 0, 0, 0, location), true},
+		{"saturday morning follows friday", time.Date(2024, 3, 23, 5, 0, 0, 0, location), true},
+		{"working saturday night", time.Date(2024, 3, 23, 23, 0, 0, 0, location), true},
+		{"sunday morning follows working saturday", time.Date(2024, 3, 24, 5, 0, 0, 0, location), true},
+		{"sunday night", time.Date(2024, 3, 24, 23, 0, 0, 0, location), false},
+		{"monday morning follows sunday", time.Date(2024, 3, 18, 3, 0, 0, 0, location), false},
+		{"shortened night ends an hour early", time.Date(2024, 3, 26, 5, 30, 0, 0, location), false},
+		{"shortened night before the hour cut", time.Date(2024, 3, 26, 4, 30, 0, 0, location), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsWorkingTime(workingTime, tt.currentTime, unusualDays); got != tt.want {
+				t.Errorf("IsWorkingTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	shorthand := ParseWorkingTime("22:00", "06:00", "Saturday,Sunday")
+	if !IsWorkingTime(shorthand, time.Date(2024, 3, 19, 3, 0, 0, 0, location), nil) {
+		t.Error("expected START_TIME after END_TIME to describe a night shift")
+	}
+}
+
+func TestIsWorkingTime_AlwaysOn(t *testing.T) {
+	location, _ := time.LoadLocation("Local")
+	workingTime := NewWorkingTime(AlwaysOnSchedule())
+	holiday := []dao.UnusualDay{{Date: time.Date(2024, 3, 24, 0, 0, 0, 0, location), Type: dao.UnusualDayHoliday}}
+
+	for _, currentTime := range []time.Time{
+		time.Date(2024, 3, 23, 0, 0, 0, 0, location),
+		time.Date(2024, 3, 23, 3, 0, 0, 0, location),
+		time.Date(2024, 3, 23, 23, 59, 59, 0, location),
+	} {
+		if !IsWorkingTime(workingTime, currentTime, holiday) {
+			t.Errorf("expected %v to be working time", currentTime)
+		}
+	}
+	if IsWorkingTime(workingTime, time.Date(2024, 3, 24, 12, 0, 0, 0, location), holiday) {
+		t.Error("expected holidays to stay days off in always on mode")
+	}
+	if IsRegularDay(workingTime, time.Date(2024, 3, 24, 12, 0, 0, 0, location), holiday) ||
+		!IsRegularDay(workingTime, time.Date(2024, 3, 23, 12, 0, 0, 0, location), holiday) {
+		t.Error("expected every day but the holiday to be a regular day")
+	}
+}
+
+func TestIsWorkingTime_Location(t *testing.T) {
+	tokyo, err := LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	workingTime := ParseWorkingTime("09:00", "18:00", "Saturday,Sunday").In(tokyo)
+	// a holiday stored as a date, like the rows of unusual_days
+	holiday := []dao.UnusualDay{{Date: time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC), Type: dao.UnusualDayHoliday}}
+
+	tests := []struct {
+		name        string
+		currentTime time.Time
+		want        bool
+	}{
+		{"tokyo morning is utc night", time.Date(2024, 3, 19, 1, 0, 0, 0, time.UTC), true},
+		{"utc morning is tokyo evening", time.Date(2024, 3, 18, 10, 0, 0, 0, time.UTC), false},
+		{"utc friday night is tokyo saturday", time.Date(2024, 3, 22, 23, 0, 0, 0, time.UTC), false},
+		{"utc monday midnight is tokyo morning", time.Date(2024, 3, 25, 0, 30, 0, 0, time.UTC), true},
+		{"holiday starts at tokyo midnight", time.Date(2024, 3, 20, 1, 0, 0, 0, time.UTC), false},
+		{"holiday is over at tokyo midnight", time.Date(2024, 3, 21, 0, 30, 0, 0, time.UTC), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsWorkingTime(workingTime, tt.currentTime, holiday); got != tt.want {
+				t.Errorf("IsWorkingTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadLocation(t *testing.T) {
+	if location, err := LoadLocation(""); err != nil || location != time.Local {
+		t.Errorf("expected the local time zone by default, got %v, %v", location, err)
+	}
+	if location, err := LoadLocation("America/New_York"); err != nil || location.String() != "America/New_York" {
+		t.Errorf("unexpected location %v, %v", location, err)
+	}
+	if _, err := LoadLocation("Mars/Olympus_Mons"); err == nil {
+		t.Error("expected an error for an unknown time zone")
+	}
+}